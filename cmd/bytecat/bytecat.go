@@ -0,0 +1,88 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/chrisfenner/bytecolor/pkg/contrast"
+	"github.com/chrisfenner/bytecolor/pkg/hexdump"
+	"github.com/chrisfenner/bytecolor/pkg/registry"
+	"github.com/chrisfenner/bytecolor/pkg/termcolor"
+)
+
+var (
+	palette   = flag.String("palette", "hsv", "which color palette to use")
+	seek      = flag.Int64("s", 0, "start at this offset")
+	length    = flag.Int64("l", 0, "stop after this many bytes (0 means up to the end)")
+	columns   = flag.Int("c", 16, "bytes per line")
+	group     = flag.Int("g", 2, "bytes per group of hex digits")
+	metric    = flag.String("contrast-metric", "wcag", "how to pick black or white text for each byte ("+strings.Join(contrast.MetricNames(), " or ")+")")
+	colorMode = flag.String("color", "auto", "terminal color support ("+strings.Join(termcolor.ModeNames(), ", ")+")")
+)
+
+func main() {
+	retval := 0
+	err := mainWithError()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		retval = -1
+	}
+	os.Exit(retval)
+}
+
+func mainWithError() error {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] [file]\n\nWith no file, or when file is -, read standard input.\n\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() > 1 {
+		return fmt.Errorf("please provide at most one input file")
+	}
+	if *seek < 0 || *length < 0 || *columns <= 0 || *group <= 0 {
+		return fmt.Errorf("-s and -l must not be negative, and -c and -g must be positive")
+	}
+	pal, err := registry.New(*palette)
+	if err != nil {
+		return err
+	}
+	mode, err := termcolor.Parse(*colorMode)
+	if err != nil {
+		return err
+	}
+	m, err := contrast.ParseMetric(*metric)
+	if err != nil {
+		return err
+	}
+
+	var r io.Reader = os.Stdin
+	if name := flag.Arg(0); name != "" && name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if _, err := f.Seek(*seek, io.SeekStart); err != nil {
+			return err
+		}
+		r = f
+	} else if *seek > 0 {
+		if _, err := io.CopyN(ioutil.Discard, r, *seek); err != nil && err != io.EOF {
+			return err
+		}
+	}
+	if *length > 0 {
+		r = io.LimitReader(r, *length)
+	}
+
+	return hexdump.Dump(os.Stdout, r, *seek, pal, &hexdump.Options{
+		Columns: *columns,
+		Group:   *group,
+		Color:   termcolor.New(mode, pal),
+		Metric:  m,
+	})
+}
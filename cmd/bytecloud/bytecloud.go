@@ -0,0 +1,84 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/chrisfenner/bytecolor/pkg/registry"
+	"github.com/chrisfenner/bytecolor/pkg/trigram"
+)
+
+var (
+	palette = flag.String("palette", "hsv", "which color palette to use")
+	in      = flag.String("in", "", "the path of the input file")
+	out     = flag.String("out", "", "the path of the output file, ending in .ply or .obj (default: derived from the input file)")
+	voxel   = flag.Int("voxel", 0, "if nonzero, aggregate the points into voxels this many bytes wide")
+	offset  = flag.Int64("offset", 0, "offset of the first byte to use")
+	length  = flag.Int64("length", 0, "number of bytes to use (0 means up to the end)")
+)
+
+func main() {
+	retval := 0
+	err := mainWithError()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		retval = -1
+	}
+	os.Exit(retval)
+}
+
+func mainWithError() error {
+	flag.Parse()
+	if *in == "" {
+		return fmt.Errorf("please provide an input file")
+	}
+	pal, err := registry.New(*palette)
+	if err != nil {
+		return err
+	}
+	outfile := *out
+	if outfile == "" {
+		outfile = fmt.Sprintf("%s-%s.ply", path.Base(*in), strings.ToLower(*palette))
+	}
+	var write func(f *os.File, points []trigram.Point) error
+	switch strings.ToLower(path.Ext(outfile)) {
+	case ".ply":
+		write = func(f *os.File, points []trigram.Point) error { return trigram.WritePLY(f, points, pal) }
+	case ".obj":
+		write = func(f *os.File, points []trigram.Point) error { return trigram.WriteOBJ(f, points, pal) }
+	default:
+		return fmt.Errorf("unrecognized output format '%s', only .ply or .obj are supported", path.Ext(outfile))
+	}
+
+	data, err := ioutil.ReadFile(*in)
+	if err != nil {
+		return err
+	}
+	if *offset < 0 || *offset > int64(len(data)) {
+		return fmt.Errorf("offset %d is outside the file (%d bytes)", *offset, len(data))
+	}
+	data = data[*offset:]
+	if *length > 0 && *length < int64(len(data)) {
+		data = data[:*length]
+	}
+
+	points, err := trigram.Points(data, *voxel)
+	if err != nil {
+		return err
+	}
+	w, err := os.Create(outfile)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+	if err := write(w, points); err != nil {
+		return err
+	}
+
+	fmt.Printf("wrote %d points from %s to %s.\n", len(points), *in, outfile)
+	return nil
+}
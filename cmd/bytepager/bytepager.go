@@ -0,0 +1,427 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/chrisfenner/bytecolor/pkg/contrast"
+	"github.com/chrisfenner/bytecolor/pkg/hexdump"
+	"github.com/chrisfenner/bytecolor/pkg/registry"
+	"github.com/chrisfenner/bytecolor/pkg/termcolor"
+	"golang.org/x/term"
+)
+
+var (
+	palette   = flag.String("palette", "hsv", "which color palette to use")
+	metric    = flag.String("contrast-metric", "wcag", "how to pick black or white text for each byte ("+strings.Join(contrast.MetricNames(), " or ")+")")
+	colorMode = flag.String("color", "auto", "terminal color support ("+strings.Join(termcolor.ModeNames(), ", ")+")")
+)
+
+func main() {
+	retval := 0
+	err := mainWithError()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		retval = -1
+	}
+	os.Exit(retval)
+}
+
+func mainWithError() error {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] file\n\n%s\n", os.Args[0], help)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		return fmt.Errorf("please provide exactly one file")
+	}
+	pal, err := registry.New(*palette)
+	if err != nil {
+		return err
+	}
+	mode, err := termcolor.Parse(*colorMode)
+	if err != nil {
+		return err
+	}
+	m, err := contrast.ParseMetric(*metric)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(flag.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	restore, err := makeRaw()
+	if err != nil {
+		return fmt.Errorf("setting up the terminal: %w", err)
+	}
+	defer restore()
+	out := bufio.NewWriter(os.Stdout)
+	// Switch to the alternate screen and hide the cursor, and undo both on exit.
+	fmt.Fprint(out, "\x1b[?1049h\x1b[?25l")
+	defer func() {
+		fmt.Fprint(out, "\x1b[?25h\x1b[?1049l")
+		out.Flush()
+	}()
+
+	pg := &pager{
+		file:   f,
+		size:   info.Size(),
+		name:   info.Name(),
+		pal:    pal,
+		term:   termcolor.New(mode, pal),
+		metric: m,
+		out:    out,
+	}
+	keys := make(chan keyEvent)
+	go readKeys(keys)
+	resized := make(chan struct{}, 1)
+	notifyResize(resized)
+
+	pg.resize()
+	for {
+		pg.draw()
+		select {
+		case k, ok := <-keys:
+			if !ok {
+				return nil
+			}
+			if quit := pg.handle(k); quit {
+				return nil
+			}
+		case <-resized:
+			pg.resize()
+		}
+	}
+}
+
+const help = `keys: j/k or arrows scroll, space/b or PgDn/PgUp page, g/G top/end,
+      :offset jumps (hex with 0x), /text searches ASCII, \hex searches bytes,
+      n/N next/previous match, v toggles the hex and dense views, q quits`
+
+type pager struct {
+	file io.ReaderAt
+	size int64
+	name string
+	pal  registry.Palette
+	term *termcolor.Terminal
+	// metric picks the text color for each byte.
+	metric contrast.Metric
+	out    *bufio.Writer
+
+	width, height int
+	dense         bool
+	// top is the offset of the first byte on the screen.
+	top int64
+
+	// prompt is the line being typed after ':', '/' or '\', if any.
+	prompting bool
+	prompt    []byte
+	kind      byte
+
+	pattern []byte
+	match   int64
+	status  string
+
+	// lines is what is on the screen, one string per line, so that draw
+	// only has to rewrite the lines that change.
+	lines []string
+}
+
+func (pg *pager) resize() {
+	w, h, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || w <= 0 || h <= 0 {
+		w, h = 80, 24
+	}
+	pg.width, pg.height = w, h
+	pg.top -= pg.top % int64(pg.perRow())
+	// Everything moves, so start again from a blank screen.
+	fmt.Fprint(pg.out, "\x1b[H\x1b[2J")
+	pg.lines = nil
+}
+
+// rows is the number of rows of data on the screen, leaving the status line.
+func (pg *pager) rows() int {
+	if pg.height < 2 {
+		return 1
+	}
+	return pg.height - 1
+}
+
+// perRow returns the number of bytes on each row in the current view.
+func (pg *pager) perRow() int {
+	if pg.dense {
+		if pg.width < 1 {
+			return 1
+		}
+		return pg.width
+	}
+	// A 16-byte hex line takes 10 + 16*2 + 8 + 1 + 16 = 67 columns.
+	if pg.width < 67 {
+		return 8
+	}
+	return 16
+}
+
+func (pg *pager) page() int64 {
+	return int64(pg.rows() * pg.perRow())
+}
+
+// scrollTo moves the top of the screen to the row holding offset, clamped
+// so that the screen does not scroll past the end of the file.
+func (pg *pager) scrollTo(offset int64) {
+	last := pg.size - pg.page()
+	if offset > last {
+		offset = last
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	pg.top = offset - offset%int64(pg.perRow())
+}
+
+func (pg *pager) highlight(address int64) bool {
+	return pg.pattern != nil && pg.match >= 0 && address >= pg.match && address < pg.match+int64(len(pg.pattern))
+}
+
+// draw updates the screen, rewriting only the lines that changed since the
+// last time, so that scrolling does not flicker.
+func (pg *pager) draw() {
+	perRow := pg.perRow()
+	buf := make([]byte, perRow)
+	opts := &hexdump.Options{
+		Columns:   perRow,
+		Highlight: pg.highlight,
+		Color:     pg.term,
+		Metric:    pg.metric,
+	}
+	lines := make([]string, pg.height)
+	for row := 0; row < pg.rows(); row++ {
+		address := pg.top + int64(row*perRow)
+		if address >= pg.size {
+			break
+		}
+		n, err := pg.file.ReadAt(buf, address)
+		if err != nil && err != io.EOF {
+			pg.status = err.Error()
+			break
+		}
+		if pg.dense {
+			var sb strings.Builder
+			for i, b := range buf[:n] {
+				if pg.highlight(address + int64(i)) {
+					sb.WriteString(opts.Highlighted(pg.pal, b, "*"))
+				} else {
+					sb.WriteString(opts.Cell(pg.pal, b, " "))
+				}
+			}
+			lines[row] = sb.String()
+		} else {
+			lines[row] = hexdump.Line(buf[:n], address, pg.pal, opts)
+		}
+	}
+
+	var status string
+	if pg.prompting {
+		status = string(pg.kind) + string(pg.prompt)
+	} else {
+		view := "hex"
+		if pg.dense {
+			view = "dense"
+		}
+		status = fmt.Sprintf("%s  0x%08x/0x%08x  %s view", pg.name, pg.top, pg.size, view)
+		if pg.status != "" {
+			status += "  " + pg.status
+		}
+		status += "  (h for help)"
+	}
+	if len(status) > pg.width {
+		status = status[:pg.width]
+	}
+	lines[pg.height-1] = "\x1b[7m" + status + strings.Repeat(" ", pg.width-len(status)) + "\x1b[0m"
+
+	for i, line := range lines {
+		if i < len(pg.lines) && pg.lines[i] == line {
+			continue
+		}
+		// Clear whatever is left of the old line after the new one.
+		fmt.Fprintf(pg.out, "\x1b[%d;1H%s\x1b[K", i+1, line)
+	}
+	pg.lines = lines
+	pg.out.Flush()
+}
+
+// handle processes one key press and reports whether to quit.
+func (pg *pager) handle(k keyEvent) bool {
+	if k.key == keyInterrupt {
+		return true
+	}
+	if pg.prompting {
+		switch k.key {
+		case keyEnter:
+			pg.prompting = false
+			pg.submit(string(pg.prompt))
+		case keyEscape:
+			pg.prompting = false
+		case keyBackspace:
+			if len(pg.prompt) > 0 {
+				pg.prompt = pg.prompt[:len(pg.prompt)-1]
+			}
+		case keyRune:
+			pg.prompt = append(pg.prompt, k.rune)
+		}
+		return false
+	}
+
+	pg.status = ""
+	perRow := int64(pg.perRow())
+	switch k.key {
+	case keyUp:
+		pg.scrollTo(pg.top - perRow)
+	case keyDown:
+		pg.scrollTo(pg.top + perRow)
+	case keyPageUp:
+		pg.scrollTo(pg.top - pg.page())
+	case keyPageDown:
+		pg.scrollTo(pg.top + pg.page())
+	case keyHome:
+		pg.scrollTo(0)
+	case keyEnd:
+		pg.scrollTo(pg.size)
+	case keyRune:
+		switch k.rune {
+		case 'q':
+			return true
+		case 'k':
+			pg.scrollTo(pg.top - perRow)
+		case 'j':
+			pg.scrollTo(pg.top + perRow)
+		case 'b':
+			pg.scrollTo(pg.top - pg.page())
+		case ' ', 'f':
+			pg.scrollTo(pg.top + pg.page())
+		case 'g':
+			pg.scrollTo(0)
+		case 'G':
+			pg.scrollTo(pg.size)
+		case 'v':
+			pg.dense = !pg.dense
+			pg.scrollTo(pg.top)
+		case 'n':
+			pg.find(true)
+		case 'N':
+			pg.find(false)
+		case 'h':
+			pg.status = strings.Join(strings.Fields(help), " ")
+		case ':', '/', '\\':
+			pg.prompting = true
+			pg.kind = k.rune
+			pg.prompt = nil
+		}
+	}
+	return false
+}
+
+func (pg *pager) submit(text string) {
+	switch pg.kind {
+	case ':':
+		offset, err := strconv.ParseInt(strings.TrimSpace(text), 0, 64)
+		if err != nil || offset < 0 || offset >= pg.size {
+			pg.status = fmt.Sprintf("bad offset '%s'", text)
+			return
+		}
+		pg.scrollTo(offset)
+	case '/':
+		pg.search([]byte(text))
+	case '\\':
+		pattern, err := hex.DecodeString(strings.Join(strings.Fields(text), ""))
+		if err != nil {
+			pg.status = fmt.Sprintf("bad hex '%s'", text)
+			return
+		}
+		pg.search(pattern)
+	}
+}
+
+func (pg *pager) search(pattern []byte) {
+	if len(pattern) == 0 {
+		pg.pattern = nil
+		return
+	}
+	// Start from the top of the screen, so that a match on screen is found.
+	pg.seek(pattern, pg.top-1, true)
+}
+
+// searchChunk is the number of bytes read at a time while searching.
+const searchChunk = 1 << 20
+
+// find moves to the next (or previous) match of the current pattern.
+func (pg *pager) find(forward bool) {
+	if pg.pattern == nil {
+		pg.status = "no pattern"
+		return
+	}
+	pg.seek(pg.pattern, pg.match, forward)
+}
+
+// seek looks for pattern after (or before) the match at offset match, and
+// makes it the current pattern and match only if it is found, so that a
+// failed search leaves the previous one in place.
+func (pg *pager) seek(pattern []byte, match int64, forward bool) {
+	n := int64(len(pattern))
+	buf := make([]byte, searchChunk+n-1)
+	var found int64 = -1
+	if forward {
+		for start := match + 1; start < pg.size && found < 0; start += searchChunk {
+			m, err := pg.file.ReadAt(buf, start)
+			if err != nil && err != io.EOF {
+				pg.status = err.Error()
+				return
+			}
+			if i := bytes.Index(buf[:m], pattern); i >= 0 {
+				found = start + int64(i)
+			}
+		}
+	} else {
+		// Chunks end just before the last byte of the current match.
+		for end := match + n - 1; end > 0 && found < 0; end -= searchChunk {
+			start := end - int64(len(buf))
+			if start < 0 {
+				start = 0
+			}
+			m, err := pg.file.ReadAt(buf[:end-start], start)
+			if err != nil && err != io.EOF {
+				pg.status = err.Error()
+				return
+			}
+			if i := bytes.LastIndex(buf[:m], pattern); i >= 0 {
+				found = start + int64(i)
+			}
+		}
+	}
+	if found < 0 {
+		pg.status = "pattern not found"
+		return
+	}
+	pg.pattern = pattern
+	pg.match = found
+	pg.status = fmt.Sprintf("match at 0x%x", found)
+	if found < pg.top || found+n > pg.top+pg.page() {
+		pg.scrollTo(found - found%int64(pg.perRow()) - int64(pg.rows()/2*pg.perRow()))
+	}
+}
@@ -0,0 +1,30 @@
+//go:build windows || plan9
+// +build windows plan9
+
+package main
+
+import (
+	"os"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// notifyResize sends on the channel whenever the terminal is resized.
+// There is no resize signal on this platform, so poll for it instead.
+func notifyResize(resized chan<- struct{}) {
+	go func() {
+		fd := int(os.Stdout.Fd())
+		w, h, _ := term.GetSize(fd)
+		for range time.Tick(500 * time.Millisecond) {
+			nw, nh, _ := term.GetSize(fd)
+			if nw != w || nh != h {
+				w, h = nw, nh
+				select {
+				case resized <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+}
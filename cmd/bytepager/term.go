@@ -0,0 +1,107 @@
+package main
+
+import (
+	"os"
+
+	"golang.org/x/term"
+)
+
+// makeRaw puts the terminal in raw mode and returns a function that restores
+// its previous state.
+func makeRaw() (func(), error) {
+	fd := int(os.Stdin.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		term.Restore(fd, state)
+	}, nil
+}
+
+type key int
+
+const (
+	keyRune key = iota
+	keyUp
+	keyDown
+	keyPageUp
+	keyPageDown
+	keyHome
+	keyEnd
+	keyEnter
+	keyBackspace
+	keyEscape
+	keyInterrupt
+)
+
+type keyEvent struct {
+	key  key
+	rune byte
+}
+
+// readKeys decodes key presses from stdin until it fails.
+func readKeys(keys chan<- keyEvent) {
+	buf := make([]byte, 256)
+	for {
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			close(keys)
+			return
+		}
+		for _, k := range decode(buf[:n]) {
+			keys <- k
+		}
+	}
+}
+
+// escapes maps the ANSI sequences of the special keys we use.
+var escapes = map[string]key{
+	"\x1b[A":  keyUp,
+	"\x1b[B":  keyDown,
+	"\x1b[5~": keyPageUp,
+	"\x1b[6~": keyPageDown,
+	"\x1b[H":  keyHome,
+	"\x1b[F":  keyEnd,
+	"\x1b[1~": keyHome,
+	"\x1b[4~": keyEnd,
+	"\x1bOA":  keyUp,
+	"\x1bOB":  keyDown,
+	"\x1bOH":  keyHome,
+	"\x1bOF":  keyEnd,
+}
+
+func decode(b []byte) []keyEvent {
+	var result []keyEvent
+	for len(b) > 0 {
+		if b[0] == 0x1b && len(b) > 1 && (b[1] == '[' || b[1] == 'O') {
+			// Sequences end with a byte in the range 0x40-0x7e.
+			end := 2
+			for end < len(b) && (b[end] < 0x40 || b[end] > 0x7e) {
+				end++
+			}
+			if end < len(b) {
+				end++
+			}
+			if k, ok := escapes[string(b[:end])]; ok {
+				result = append(result, keyEvent{key: k})
+			}
+			b = b[end:]
+			continue
+		}
+		switch b[0] {
+		case 0x1b:
+			result = append(result, keyEvent{key: keyEscape})
+		case '\r', '\n':
+			result = append(result, keyEvent{key: keyEnter})
+		case 0x7f, 0x08:
+			result = append(result, keyEvent{key: keyBackspace})
+		case 0x03:
+			result = append(result, keyEvent{key: keyInterrupt})
+		default:
+			result = append(result, keyEvent{key: keyRune, rune: b[0]})
+		}
+		b = b[1:]
+	}
+	return result
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/chrisfenner/bytecolor/pkg/digraph"
+	"github.com/chrisfenner/bytecolor/pkg/entropy"
+	"github.com/chrisfenner/bytecolor/pkg/layout"
+	"github.com/chrisfenner/bytecolor/pkg/registry"
+	"github.com/chrisfenner/bytecolor/pkg/render"
+)
+
+var (
+	palette     = flag.String("palette", "hsv", "which color palette to use")
+	in          = flag.String("in", "", "the path of the input file")
+	out         = flag.String("out", "", "the path of the output PNG (default: derived from the input file)")
+	layoutName  = flag.String("layout", "rowmajor", "how to arrange the bytes ("+strings.Join(layout.Names(), ", ")+")")
+	width       = flag.Int("width", 256, "bytes per row, for the rowmajor and boustrophedon layouts")
+	record      = flag.Int("record", 16, "record size in bytes, for the columnmajor layout")
+	height      = flag.Int("height", 1024, "records per band, for the columnmajor layout")
+	scale       = flag.Int("scale", 1, "width and height in pixels of each byte")
+	offset      = flag.Int64("offset", 0, "offset of the first byte to render")
+	length      = flag.Int64("length", 0, "number of bytes to render (0 means up to the end)")
+	entropyMode = flag.String("entropy", "none", "how to show entropy (none, band or overlay)")
+	window      = flag.Int("window", 256, "size in bytes of the entropy window")
+	step        = flag.Int("step", 64, "distance in bytes between entropy windows")
+	csvOut      = flag.String("csv", "", "also write the entropy series as CSV to this path")
+	view        = flag.String("view", "bytes", "what to draw (bytes, or digraph for a byte-pair density plot)")
+	stream      = flag.Bool("stream", false, "render row by row with bounded memory (rowmajor layout only, for very large files)")
+	bpp         = flag.Int64("bpp", 1, "bytes summarized by each pixel")
+	rows        = flag.Int("rows", 0, "if nonzero, pick the bytes per pixel so the rowmajor image is at most this many rows tall")
+	aggregate   = flag.String("aggregate", "frequent", "how each pixel summarizes its bytes ("+strings.Join(render.AggregateNames(), ", ")+")")
+)
+
+func main() {
+	retval := 0
+	err := mainWithError()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		retval = -1
+	}
+	os.Exit(retval)
+}
+
+func mainWithError() error {
+	flag.Parse()
+	if *in == "" {
+		return fmt.Errorf("please provide an input file")
+	}
+	if err := checkRange(); err != nil {
+		return err
+	}
+
+	pal, err := registry.New(*palette)
+	if err != nil {
+		return err
+	}
+	if *stream {
+		return streamFile(pal)
+	}
+	l, err := layout.ByName(*layoutName, layout.Params{
+		Width:      *width,
+		RecordSize: *record,
+		Height:     *height,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := ioutil.ReadFile(*in)
+	if err != nil {
+		return err
+	}
+	var m image.Image
+	var series []float64
+	kind := strings.ToLower(*layoutName)
+	switch strings.ToLower(*view) {
+	case "bytes":
+		m, series, err = renderBytes(data, pal, l)
+	case "digraph":
+		kind = "digraph"
+		m, err = digraph.Image(digraph.Count(selected(data)), pal, &digraph.Options{
+			Scale: *scale,
+		})
+	default:
+		return fmt.Errorf("unrecognized view '%s', only 'bytes' or 'digraph' are supported", *view)
+	}
+	if err != nil {
+		return err
+	}
+
+	if *csvOut != "" {
+		if series == nil {
+			if series, err = entropy.Series(selected(data), *window, *step); err != nil {
+				return err
+			}
+		}
+		if err := writeCSV(*csvOut, series, len(selected(data))); err != nil {
+			return err
+		}
+	}
+
+	outfile := outName(kind)
+	w, err := os.Create(outfile)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+	if err := png.Encode(w, m); err != nil {
+		return err
+	}
+
+	fmt.Printf("rendered %s as a %dx%d %s image in %s.\n", *in, m.Bounds().Dx(), m.Bounds().Dy(), kind, outfile)
+	return nil
+}
+
+func renderBytes(data []byte, pal registry.Palette, l layout.Constructor) (image.Image, []float64, error) {
+	agg, err := render.ParseAggregate(*aggregate)
+	if err != nil {
+		return nil, nil, err
+	}
+	n := *bpp
+	if *rows > 0 {
+		n = render.BytesPerPixel(int64(len(selected(data))), *width, *rows)
+	}
+	opts := &render.Options{
+		Scale:         *scale,
+		Offset:        *offset,
+		Length:        *length,
+		Layout:        l,
+		BytesPerPixel: n,
+		Aggregate:     agg,
+	}
+	switch strings.ToLower(*entropyMode) {
+	case "none":
+		m, err := render.Overview(data, pal, *width, opts)
+		return m, nil, err
+	case "band":
+		return render.WithEntropy(data, pal, *width, opts, &render.EntropyOptions{
+			Window: *window,
+			Step:   *step,
+			Mode:   render.EntropyBand,
+		})
+	case "overlay":
+		return render.WithEntropy(data, pal, *width, opts, &render.EntropyOptions{
+			Window: *window,
+			Step:   *step,
+			Mode:   render.EntropyOverlay,
+		})
+	default:
+		return nil, nil, fmt.Errorf("unrecognized entropy option '%s', only 'none', 'band' or 'overlay' are supported", *entropyMode)
+	}
+}
+
+// checkRange makes sure that the offset and length flags pick at least one
+// byte of the input file, so that every view renders the same bytes.
+func checkRange() error {
+	info, err := os.Stat(*in)
+	if err != nil {
+		return err
+	}
+	if *offset < 0 || *offset >= info.Size() {
+		return fmt.Errorf("offset %d is outside the file (%d bytes)", *offset, info.Size())
+	}
+	if *length < 0 {
+		return fmt.Errorf("length %d is negative", *length)
+	}
+	return nil
+}
+
+// selected returns the part of the data picked by the offset and length
+// flags, which checkRange has checked.
+func selected(data []byte) []byte {
+	data = data[*offset:]
+	if *length > 0 && *length < int64(len(data)) {
+		data = data[:*length]
+	}
+	return data
+}
+
+func writeCSV(path string, series []float64, n int) error {
+	w, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := entropy.WriteCSV(w, series, *offset, *window, *step, n); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+func outName(kind string) string {
+	if *out != "" {
+		return *out
+	}
+	return fmt.Sprintf("%s-%s-%s.png", path.Base(*in), kind, strings.ToLower(*palette))
+}
+
+func streamFile(pal registry.Palette) error {
+	if strings.ToLower(*layoutName) != "rowmajor" {
+		return fmt.Errorf("only the rowmajor layout can be streamed")
+	}
+	f, err := os.Open(*in)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	size := info.Size()
+	if _, err := f.Seek(*offset, io.SeekStart); err != nil {
+		return err
+	}
+	size -= *offset
+	if *length > 0 && *length < size {
+		size = *length
+	}
+
+	agg, err := render.ParseAggregate(*aggregate)
+	if err != nil {
+		return err
+	}
+
+	outfile := outName("stream")
+	w, err := os.Create(outfile)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+	opts := &render.StreamOptions{
+		Size:          size,
+		BytesPerPixel: *bpp,
+		Height:        *rows,
+		Aggregate:     agg,
+	}
+	if err := render.Stream(w, bufio.NewReader(f), pal, *width, opts); err != nil {
+		return err
+	}
+
+	n := *bpp
+	if *rows > 0 {
+		n = render.BytesPerPixel(size, *width, *rows)
+	}
+	fmt.Printf("streamed %d bytes of %s at %d bytes per pixel (%s) to %s.\n", size, *in, n, agg, outfile)
+	return nil
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/chrisfenner/bytecolor/pkg/halfblock"
+	"github.com/chrisfenner/bytecolor/pkg/registry"
+	"github.com/chrisfenner/bytecolor/pkg/render"
+	"github.com/chrisfenner/bytecolor/pkg/termcolor"
+	"golang.org/x/term"
+)
+
+var (
+	palette   = flag.String("palette", "hsv", "which color palette to use for binary files")
+	width     = flag.Int("width", 0, "width of the thumbnail in characters (0 means the terminal width)")
+	height    = flag.Int("height", 0, "height of the thumbnail in lines (0 means the terminal height)")
+	aggregate = flag.String("aggregate", "frequent", "how each pixel summarizes its bytes ("+strings.Join(render.AggregateNames(), ", ")+")")
+	raw       = flag.Bool("raw", false, "draw the bytes of the file even if it is an image")
+	colorMode = flag.String("color", "auto", "terminal color support ("+strings.Join(termcolor.ModeNames(), ", ")+")")
+)
+
+func main() {
+	retval := 0
+	err := mainWithError()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		retval = -1
+	}
+	os.Exit(retval)
+}
+
+func mainWithError() error {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] file\n\nImages (PNG, GIF or JPEG) are drawn as they are, other files as their bytes.\n\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		return fmt.Errorf("please provide exactly one file")
+	}
+	cols, rows, err := size()
+	if err != nil {
+		return err
+	}
+	data, err := ioutil.ReadFile(flag.Arg(0))
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 {
+		return fmt.Errorf("%s is empty", flag.Arg(0))
+	}
+
+	pal, err := registry.New(*palette)
+	if err != nil {
+		return err
+	}
+	mode, err := termcolor.Parse(*colorMode)
+	if err != nil {
+		return err
+	}
+	term := termcolor.New(mode, pal)
+
+	if !*raw {
+		if m, _, err := image.Decode(bytes.NewReader(data)); err == nil {
+			return halfblock.Write(os.Stdout, halfblock.Fit(m, cols, rows), term)
+		}
+	}
+
+	agg, err := render.ParseAggregate(*aggregate)
+	if err != nil {
+		return err
+	}
+	// Each character cell holds two pixels, one above the other.
+	w := cols
+	if len(data) < w {
+		w = len(data)
+	}
+	m, err := render.Overview(data, pal, w, &render.Options{
+		BytesPerPixel: render.BytesPerPixel(int64(len(data)), w, 2*rows),
+		Aggregate:     agg,
+	})
+	if err != nil {
+		return err
+	}
+	return halfblock.Write(os.Stdout, m, term)
+}
+
+// size returns the size of the thumbnail in character cells.
+func size() (int, int, error) {
+	cols, rows := *width, *height
+	if cols == 0 || rows == 0 {
+		w, h, err := term.GetSize(int(os.Stdout.Fd()))
+		if err != nil {
+			return 0, 0, fmt.Errorf("getting the terminal size (try -width and -height): %w", err)
+		}
+		if cols == 0 {
+			cols = w
+		}
+		if rows == 0 {
+			// Leave a line for the prompt.
+			rows = h - 1
+		}
+	}
+	if cols <= 0 || rows <= 0 {
+		return 0, 0, fmt.Errorf("the thumbnail must be at least one character wide and tall")
+	}
+	return cols, rows, nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/chrisfenner/bytecolor/pkg/registry"
+	"github.com/chrisfenner/bytecolor/pkg/render"
+	"github.com/chrisfenner/bytecolor/pkg/tiles"
+)
+
+var (
+	palette   = flag.String("palette", "hsv", "which color palette to use")
+	in        = flag.String("in", "", "the path of the input file")
+	out       = flag.String("out", "", "the directory to write the tiles to (default: derived from the input file)")
+	width     = flag.Int("width", 1024, "bytes per row at full resolution")
+	tileSize  = flag.Int("tile", 256, "width and height of each tile")
+	format    = flag.String("format", "xyz", "directory layout of the tiles (xyz or dzi)")
+	aggregate = flag.String("aggregate", "frequent", "how lower zoom levels summarize their bytes ("+strings.Join(render.AggregateNames(), ", ")+")")
+	workers   = flag.Int("workers", 0, "number of tiles to generate in parallel (0 means one per CPU)")
+)
+
+func main() {
+	retval := 0
+	err := mainWithError()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		retval = -1
+	}
+	os.Exit(retval)
+}
+
+func mainWithError() error {
+	flag.Parse()
+	if *in == "" {
+		return fmt.Errorf("please provide an input file")
+	}
+	pal, err := registry.New(*palette)
+	if err != nil {
+		return err
+	}
+	agg, err := render.ParseAggregate(*aggregate)
+	if err != nil {
+		return err
+	}
+	opts := &tiles.Options{
+		Width:     *width,
+		TileSize:  *tileSize,
+		Aggregate: agg,
+		Workers:   *workers,
+		Progress: func(done, total int) {
+			if done%100 == 0 || done == total {
+				fmt.Fprintf(os.Stderr, "\r%d/%d tiles", done, total)
+			}
+		},
+	}
+	switch strings.ToLower(*format) {
+	case "xyz":
+		opts.Format = tiles.XYZ
+	case "dzi":
+		opts.Format = tiles.DeepZoom
+	default:
+		return fmt.Errorf("unrecognized format '%s', only 'xyz' or 'dzi' are supported", *format)
+	}
+
+	f, err := os.Open(*in)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	dir := *out
+	if dir == "" {
+		dir = fmt.Sprintf("%s-%s-tiles", info.Name(), strings.ToLower(*palette))
+	}
+
+	// Stop cleanly on Ctrl-C; finished tiles are kept, so running the same
+	// command again picks up where this one left off.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := tiles.Generate(ctx, f, info.Size(), pal, dir, opts); err != nil {
+		fmt.Fprintf(os.Stderr, "\n")
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "\n")
+	fmt.Printf("wrote %s tiles for %s to %s.\n", strings.ToLower(*format), *in, dir)
+	return nil
+}
@@ -0,0 +1,340 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"hash/fnv"
+	"image/png"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/chrisfenner/bytecolor/pkg/contrast"
+	"github.com/chrisfenner/bytecolor/pkg/registry"
+	"github.com/chrisfenner/bytecolor/pkg/render"
+	"github.com/chrisfenner/bytecolor/pkg/tiles"
+)
+
+var (
+	palette   = flag.String("palette", "hsv", "which color palette to start with")
+	in        = flag.String("in", "", "the path of the file to view")
+	addr      = flag.String("addr", "127.0.0.1:8080", "the address to serve on")
+	width     = flag.Int("width", 1024, "bytes per row")
+	aggregate = flag.String("aggregate", "frequent", "how zoomed-out pixels summarize their bytes ("+strings.Join(render.AggregateNames(), ", ")+")")
+	metric    = flag.String("contrast-metric", "wcag", "how to pick black or white text for each byte ("+strings.Join(contrast.MetricNames(), " or ")+")")
+)
+
+// Everything the browser needs is embedded, so the viewer works without
+// network access beyond localhost.
+//
+//go:embed static
+var static embed.FS
+
+// maxBytes is the most bytes returned by a single /api/bytes request.
+const maxBytes = 64 * 1024
+
+// cachedFactor is the smallest downsampling factor whose tiles are kept in
+// memory once drawn. Such a tile summarizes at least cachedFactor² leaf
+// tiles' worth of the file, and there are few of them.
+const cachedFactor = 16
+
+func main() {
+	retval := 0
+	err := mainWithError()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		retval = -1
+	}
+	os.Exit(retval)
+}
+
+func mainWithError() error {
+	flag.Parse()
+	if *in == "" {
+		return fmt.Errorf("please provide a file to view")
+	}
+	if _, err := registry.New(*palette); err != nil {
+		return err
+	}
+	agg, err := render.ParseAggregate(*aggregate)
+	if err != nil {
+		return err
+	}
+	m, err := contrast.ParseMetric(*metric)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(*in)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	py, err := tiles.New(info.Size(), &tiles.Options{
+		Width:     *width,
+		Aggregate: agg,
+	})
+	if err != nil {
+		return err
+	}
+
+	// The tiles depend on the file and the options it is viewed with, so
+	// browsers must not reuse tiles from another run unless all of them match.
+	abs, err := filepath.Abs(*in)
+	if err != nil {
+		return err
+	}
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%s\x00%d\x00%d\x00%d\x00%s", abs, info.Size(), info.ModTime().UnixNano(), *width, agg)
+
+	v := &viewer{
+		file:        f,
+		name:        filepath.Base(*in),
+		size:        info.Size(),
+		fingerprint: fmt.Sprintf("%016x", h.Sum64()),
+		pyramid:     py,
+		metric:      m,
+		palettes:    make(map[string]registry.Palette),
+		tiles:       make(map[tileKey]*cachedTile),
+	}
+	assets, err := fs.Sub(static, "static")
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/", http.FileServer(http.FS(assets)))
+	mux.HandleFunc("/api/info", v.serveInfo)
+	mux.HandleFunc("/api/palette", v.servePalette)
+	mux.HandleFunc("/api/bytes", v.serveBytes)
+	mux.HandleFunc("/tile/", v.serveTile)
+
+	fmt.Printf("serving %s on http://%s/\n", *in, *addr)
+	return http.ListenAndServe(*addr, checkHost(*addr, mux))
+}
+
+// checkHost rejects requests whose Host header does not name the server, so
+// that other web pages cannot reach a server on a loopback address by
+// rebinding their own host names to it. A server on all interfaces can be
+// reached by any name, and is not checked.
+func checkHost(addr string, next http.Handler) http.Handler {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil || host == "" || net.ParseIP(host).IsUnspecified() {
+		return next
+	}
+	allowed := map[string]bool{
+		strings.ToLower(host): true,
+		"localhost":           true,
+		"127.0.0.1":           true,
+		"::1":                 true,
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h, _, err := net.SplitHostPort(r.Host)
+		if err != nil {
+			h = r.Host
+		}
+		if !allowed[strings.ToLower(strings.Trim(h, "[]"))] {
+			http.Error(w, "unexpected host", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+type viewer struct {
+	file io.ReaderAt
+	name string
+	size int64
+	// fingerprint identifies the file and the options its tiles are drawn
+	// with.
+	fingerprint string
+	pyramid     *tiles.Pyramid
+	metric      contrast.Metric
+
+	mu       sync.Mutex
+	palettes map[string]registry.Palette
+	// tiles holds the coarse tiles drawn so far, which each read a large
+	// part of the file.
+	tiles map[tileKey]*cachedTile
+}
+
+type tileKey struct {
+	palette     string
+	level, x, y int
+}
+
+// cachedTile is a PNG tile, which is ready once done is closed.
+type cachedTile struct {
+	done chan struct{}
+	png  []byte
+	err  error
+}
+
+// palette returns the named palette, constructing it on first use.
+func (v *viewer) palette(name string) (registry.Palette, error) {
+	name = strings.ToLower(name)
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if p, ok := v.palettes[name]; ok {
+		return p, nil
+	}
+	p, err := registry.New(name)
+	if err != nil {
+		return nil, err
+	}
+	v.palettes[name] = p
+	return p, nil
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (v *viewer) serveInfo(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, map[string]interface{}{
+		"name":     v.name,
+		"size":     v.size,
+		"width":    *width,
+		"tileSize": v.pyramid.TileSize(),
+		"maxLevel": v.pyramid.MaxLevel(),
+		"palette":  strings.ToLower(*palette),
+		"palettes": registry.Names(),
+	})
+}
+
+// servePalette returns the 256 colors of a palette as hex strings, and the
+// color of text drawn on each of them.
+func (v *viewer) servePalette(w http.ResponseWriter, r *http.Request) {
+	p, err := v.palette(r.URL.Query().Get("name"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	colors := make([]string, 256)
+	text := make([]string, 256)
+	for i := range colors {
+		c := p.Select(byte(i))
+		t := v.metric.Text(c)
+		colors[i] = hex.EncodeToString(c[:])
+		text[i] = hex.EncodeToString(t[:])
+	}
+	writeJSON(w, map[string]interface{}{
+		"colors": colors,
+		"text":   text,
+	})
+}
+
+// serveBytes returns the hex of the bytes in [offset, offset+length).
+func (v *viewer) serveBytes(w http.ResponseWriter, r *http.Request) {
+	offset, err := strconv.ParseInt(r.URL.Query().Get("offset"), 10, 64)
+	if err != nil || offset < 0 || offset >= v.size {
+		http.Error(w, "bad offset", http.StatusBadRequest)
+		return
+	}
+	length, err := strconv.ParseInt(r.URL.Query().Get("length"), 10, 64)
+	if err != nil || length <= 0 || length > maxBytes {
+		http.Error(w, "bad length", http.StatusBadRequest)
+		return
+	}
+	if offset+length > v.size {
+		length = v.size - offset
+	}
+	buf := make([]byte, length)
+	if _, err := v.file.ReadAt(buf, offset); err != nil && err != io.EOF {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, map[string]interface{}{
+		"offset": offset,
+		"hex":    hex.EncodeToString(buf),
+	})
+}
+
+// serveTile renders /tile/<palette>/<level>/<x>/<y>.png on demand.
+func (v *viewer) serveTile(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/tile/"), ".png"), "/")
+	if len(parts) != 4 {
+		http.NotFound(w, r)
+		return
+	}
+	p, err := v.palette(parts[0])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	var nums [3]int
+	for i := range nums {
+		if nums[i], err = strconv.Atoi(parts[i+1]); err != nil {
+			http.NotFound(w, r)
+			return
+		}
+	}
+	// The tile URL does not say which file it is for, so browsers have to
+	// check with the server before reusing a tile.
+	etag := fmt.Sprintf(`"%s-%s-%d-%d-%d"`, v.fingerprint, strings.ToLower(parts[0]), nums[0], nums[1], nums[2])
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	key := tileKey{strings.ToLower(parts[0]), nums[0], nums[1], nums[2]}
+	data, err := v.tile(p, key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	if _, err := w.Write(data); err != nil {
+		fmt.Fprintf(os.Stderr, "writing tile %s: %v\n", r.URL.Path, err)
+	}
+}
+
+// tile returns the PNG of a tile. Coarse tiles are drawn once and kept, so
+// that zooming out does not read most of the file again for every tile,
+// and concurrent requests for one of them wait for the same drawing.
+func (v *viewer) tile(p registry.Palette, key tileKey) ([]byte, error) {
+	if key.level < 0 || key.level > v.pyramid.MaxLevel() || v.pyramid.Factor(key.level) < cachedFactor {
+		return v.drawTile(p, key)
+	}
+	v.mu.Lock()
+	t, ok := v.tiles[key]
+	if !ok {
+		t = &cachedTile{done: make(chan struct{})}
+		v.tiles[key] = t
+	}
+	v.mu.Unlock()
+	if !ok {
+		t.png, t.err = v.drawTile(p, key)
+		close(t.done)
+	}
+	<-t.done
+	return t.png, t.err
+}
+
+func (v *viewer) drawTile(p registry.Palette, key tileKey) ([]byte, error) {
+	m, err := v.pyramid.Tile(v.file, p, key.level, key.x, key.y, false)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, m); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,245 @@
+'use strict';
+
+// Pan/zoom viewer over the tile pyramid served by byteview.
+// World coordinates are full-resolution pixels: one byte per pixel,
+// info.width bytes per row.
+
+const canvas = document.getElementById('view');
+const ctx = canvas.getContext('2d');
+const hover = document.getElementById('hover');
+const paletteSelect = document.getElementById('palette');
+const hexpane = document.getElementById('hexpane');
+const hexdump = document.getElementById('hexdump');
+const hextitle = document.getElementById('hextitle');
+
+let info = null;
+let colors = [];
+// The color of text on each of the colors, chosen by the server.
+let textColors = [];
+let palette = '';
+// The world point shown at the top-left corner, and screen pixels per world pixel.
+const view = { x: 0, y: 0, scale: 1 };
+const tileCache = new Map();
+// Chunks of the file, fetched on demand and keyed by aligned offset.
+const CHUNK = 4096;
+const chunkCache = new Map();
+
+function rows() {
+  return Math.ceil(info.size / info.width);
+}
+
+function level() {
+  // Use the coarsest level whose pixels are still at least one screen pixel.
+  const l = info.maxLevel + Math.floor(Math.log2(view.scale));
+  return Math.max(0, Math.min(info.maxLevel, l));
+}
+
+function tile(l, x, y) {
+  const key = `${palette}/${l}/${x}/${y}`;
+  let img = tileCache.get(key);
+  if (!img) {
+    img = new Image();
+    img.onload = draw;
+    img.src = `tile/${key}.png`;
+    tileCache.set(key, img);
+  }
+  return img;
+}
+
+function draw() {
+  if (!info) {
+    return;
+  }
+  canvas.width = canvas.clientWidth;
+  canvas.height = canvas.clientHeight;
+  ctx.imageSmoothingEnabled = false;
+  ctx.fillStyle = '#222';
+  ctx.fillRect(0, 0, canvas.width, canvas.height);
+
+  const l = level();
+  const factor = 2 ** (info.maxLevel - l);
+  const span = info.tileSize * factor; // world pixels per tile
+  const levelW = Math.ceil(info.width / factor);
+  const levelH = Math.ceil(rows() / factor);
+  const across = Math.ceil(levelW / info.tileSize);
+  const down = Math.ceil(levelH / info.tileSize);
+
+  const x0 = Math.max(0, Math.floor(view.x / span));
+  const y0 = Math.max(0, Math.floor(view.y / span));
+  const x1 = Math.min(across - 1, Math.floor((view.x + canvas.width / view.scale) / span));
+  const y1 = Math.min(down - 1, Math.floor((view.y + canvas.height / view.scale) / span));
+  for (let x = x0; x <= x1; x++) {
+    for (let y = y0; y <= y1; y++) {
+      const img = tile(l, x, y);
+      if (img.complete && img.naturalWidth > 0) {
+        ctx.drawImage(img,
+          (x * span - view.x) * view.scale,
+          (y * span - view.y) * view.scale,
+          span * view.scale,
+          span * view.scale);
+      }
+    }
+  }
+}
+
+// offsetAt returns the file offset under a screen point, or -1.
+function offsetAt(sx, sy) {
+  const col = Math.floor(view.x + sx / view.scale);
+  const row = Math.floor(view.y + sy / view.scale);
+  if (col < 0 || row < 0 || col >= info.width) {
+    return -1;
+  }
+  const offset = row * info.width + col;
+  return offset < info.size ? offset : -1;
+}
+
+async function chunk(start) {
+  let p = chunkCache.get(start);
+  if (!p) {
+    p = fetch(`api/bytes?offset=${start}&length=${CHUNK}`)
+      .then((r) => r.json())
+      .then((j) => {
+        const bytes = [];
+        for (let i = 0; i < j.hex.length; i += 2) {
+          bytes.push(parseInt(j.hex.substr(i, 2), 16));
+        }
+        return bytes;
+      });
+    chunkCache.set(start, p);
+  }
+  return p;
+}
+
+async function bytesAt(offset, length) {
+  const result = [];
+  for (let start = offset - (offset % CHUNK); start < offset + length && start < info.size; start += CHUNK) {
+    const bytes = await chunk(start);
+    for (let i = 0; i < bytes.length; i++) {
+      if (start + i >= offset && start + i < offset + length) {
+        result.push(bytes[i]);
+      }
+    }
+  }
+  return result;
+}
+
+function hex2(b) {
+  return b.toString(16).padStart(2, '0');
+}
+
+function ascii(b) {
+  return b >= 0x20 && b < 0x7f ? String.fromCharCode(b) : '.';
+}
+
+let hoverSeq = 0;
+async function showHover(offset) {
+  const seq = ++hoverSeq;
+  if (offset < 0) {
+    hover.textContent = '';
+    return;
+  }
+  const [b] = await bytesAt(offset, 1);
+  if (seq !== hoverSeq || b === undefined) {
+    return;
+  }
+  hover.innerHTML = `offset 0x${offset.toString(16)} (${offset}) &nbsp; hex ${hex2(b)} &nbsp; ascii ${escape(ascii(b))} &nbsp; ` +
+    `<span class="swatch" style="background:#${colors[b]}"></span> #${colors[b]}`;
+}
+
+function escape(s) {
+  return s.replace(/[&<>"']/g, (c) => `&#${c.charCodeAt(0)};`);
+}
+
+async function showHex(offset) {
+  const start = Math.max(0, offset - (offset % 16) - 16 * 16);
+  const bytes = await bytesAt(start, 16 * 33);
+  let html = '';
+  for (let row = 0; row * 16 < bytes.length; row++) {
+    const line = bytes.slice(row * 16, row * 16 + 16);
+    html += (start + row * 16).toString(16).padStart(8, '0') + '  ';
+    line.forEach((b, i) => {
+      const sel = start + row * 16 + i === offset ? ' sel' : '';
+      html += `<span class="b${sel}" style="background:#${colors[b]};color:#${textColors[b]}">${hex2(b)}</span>`;
+      html += i === 7 ? '  ' : ' ';
+    });
+    html += ' '.repeat((16 - line.length) * 3) + ' ';
+    line.forEach((b) => {
+      html += `<span style="background:#${colors[b]};color:#${textColors[b]}">${escape(ascii(b))}</span>`;
+    });
+    html += '\n';
+  }
+  hextitle.textContent = `around 0x${offset.toString(16)}`;
+  hexdump.innerHTML = html;
+  hexpane.hidden = false;
+  draw();
+}
+
+async function loadPalette(name) {
+  const r = await fetch(`api/palette?name=${encodeURIComponent(name)}`);
+  const j = await r.json();
+  colors = j.colors;
+  textColors = j.text;
+  palette = name;
+  draw();
+}
+
+let drag = null;
+canvas.addEventListener('mousedown', (e) => {
+  drag = { x: e.offsetX, y: e.offsetY, moved: false };
+});
+window.addEventListener('mouseup', (e) => {
+  if (drag && !drag.moved && e.target === canvas) {
+    const offset = offsetAt(e.offsetX, e.offsetY);
+    if (offset >= 0) {
+      showHex(offset);
+    }
+  }
+  drag = null;
+});
+canvas.addEventListener('mousemove', (e) => {
+  if (drag) {
+    const dx = e.offsetX - drag.x;
+    const dy = e.offsetY - drag.y;
+    if (Math.abs(dx) + Math.abs(dy) > 2) {
+      drag.moved = true;
+    }
+    view.x -= dx / view.scale;
+    view.y -= dy / view.scale;
+    drag.x = e.offsetX;
+    drag.y = e.offsetY;
+    draw();
+  }
+  showHover(offsetAt(e.offsetX, e.offsetY));
+});
+canvas.addEventListener('wheel', (e) => {
+  e.preventDefault();
+  // Zoom around the point under the cursor.
+  const wx = view.x + e.offsetX / view.scale;
+  const wy = view.y + e.offsetY / view.scale;
+  const scale = view.scale * (e.deltaY < 0 ? 1.25 : 0.8);
+  view.scale = Math.max(2 ** -info.maxLevel, Math.min(64, scale));
+  view.x = wx - e.offsetX / view.scale;
+  view.y = wy - e.offsetY / view.scale;
+  draw();
+}, { passive: false });
+document.getElementById('hexclose').addEventListener('click', () => {
+  hexpane.hidden = true;
+  draw();
+});
+paletteSelect.addEventListener('change', () => loadPalette(paletteSelect.value));
+window.addEventListener('resize', draw);
+
+(async () => {
+  info = await (await fetch('api/info')).json();
+  document.getElementById('name').textContent = `${info.name} (${info.size} bytes)`;
+  for (const name of info.palettes) {
+    const opt = document.createElement('option');
+    opt.value = name;
+    opt.textContent = name;
+    opt.selected = name === info.palette;
+    paletteSelect.appendChild(opt);
+  }
+  // Start with the whole width of the file in view.
+  view.scale = Math.min(1, canvas.clientWidth / info.width);
+  await loadPalette(info.palette);
+})();
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/chrisfenner/bytecolor/pkg/conformance"
+	"github.com/chrisfenner/bytecolor/pkg/registry"
+)
+
+var (
+	defaults    = conformance.Defaults()
+	palettes    = flag.String("palettes", strings.Join(registry.Names(), ","), "comma-separated palettes to check (registered names or palette files)")
+	minDeltaE   = flag.Float64("min-delta-e", defaults.MinHammingDeltaE, "minimum CIEDE2000 difference between one-bit neighbors (0 skips the check)")
+	roundTrip   = flag.Bool("roundtrip", defaults.RoundTrip, "require Nearest(Select(b)) == b")
+	unique      = flag.Bool("unique", defaults.Unique, "require 256 distinct colors")
+	conventions = flag.Bool("conventions", defaults.Conventions, "require 0x00 to be black and 0xff to be white")
+	format      = flag.String("format", "text", "report format (text or json)")
+	maxFailures = flag.Int("max-failures", 10, "failures to list per check in the text report (0 means all)")
+)
+
+func main() {
+	retval := 0
+	err := mainWithError()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		retval = -1
+	}
+	os.Exit(retval)
+}
+
+func mainWithError() error {
+	flag.Parse()
+	t := conformance.Thresholds{
+		MinHammingDeltaE: *minDeltaE,
+		RoundTrip:        *roundTrip,
+		Unique:           *unique,
+		Conventions:      *conventions,
+	}
+
+	var reports []*conformance.Report
+	failed := 0
+	for _, spec := range strings.Split(*palettes, ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		p, err := registry.Load(spec)
+		if err != nil {
+			return err
+		}
+		r := conformance.Check(spec, p, t)
+		if !r.Passed {
+			failed++
+		}
+		reports = append(reports, r)
+	}
+	if len(reports) == 0 {
+		return fmt.Errorf("please provide at least one palette")
+	}
+
+	switch strings.ToLower(*format) {
+	case "text":
+		for _, r := range reports {
+			if err := r.WriteText(os.Stdout, *maxFailures); err != nil {
+				return err
+			}
+		}
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(reports); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported format '%s', only 'text' or 'json' are supported", *format)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d palettes failed conformance", failed, len(reports))
+	}
+	return nil
+}
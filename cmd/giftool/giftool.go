@@ -13,12 +13,7 @@ import (
 	"strings"
 
 	"github.com/chrisfenner/bytecolor/pkg/gif"
-	"github.com/chrisfenner/bytecolor/pkg/hcl"
-	"github.com/chrisfenner/bytecolor/pkg/hsl"
-	"github.com/chrisfenner/bytecolor/pkg/hsv"
-	"github.com/chrisfenner/bytecolor/pkg/luv"
-	"github.com/chrisfenner/bytecolor/pkg/tester"
-	"github.com/chrisfenner/bytecolor/pkg/windows"
+	"github.com/chrisfenner/bytecolor/pkg/registry"
 )
 
 var (
@@ -47,36 +42,9 @@ func mainWithError() error {
 		return fmt.Errorf("please provide at least one input file (comma-separated")
 	}
 
-	var pal tester.Palette
-	var err error
-	switch strings.ToLower(*palette) {
-	case "hsl":
-		pal, err = hsl.New()
-		if err != nil {
-			return err
-		}
-	case "hsv":
-		pal, err = hsv.New()
-		if err != nil {
-			return err
-		}
-	case "hcl":
-		pal, err = hcl.New()
-		if err != nil {
-			return err
-		}
-	case "luv":
-		pal, err = luv.New()
-		if err != nil {
-			return err
-		}
-	case "win":
-		pal, err = windows.New()
-		if err != nil {
-			return err
-		}
-	default:
-		return fmt.Errorf("unsupported palette '%s'", *palette)
+	pal, err := registry.New(*palette)
+	if err != nil {
+		return err
 	}
 
 	gifs := make([]*image.Paletted, len(infiles))
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/chrisfenner/bytecolor/pkg/contrast"
+	"github.com/chrisfenner/bytecolor/pkg/diff"
+	"github.com/chrisfenner/bytecolor/pkg/registry"
+	"github.com/chrisfenner/bytecolor/pkg/termcolor"
+	"github.com/chrisfenner/bytecolor/pkg/tester"
+)
+
+var (
+	oldSpec   = flag.String("old", "", "the old palette (a registered name or a palette file)")
+	newSpec   = flag.String("new", "", "the new palette (a registered name or a palette file)")
+	format    = flag.String("format", "table", "output format (table or json)")
+	all       = flag.Bool("all", false, "list unchanged bytes in the table too")
+	grid      = flag.Bool("grid", true, "print the two palettes side by side (table format only)")
+	metric    = flag.String("contrast-metric", "wcag", "how to pick black or white text for each byte of the grid ("+strings.Join(contrast.MetricNames(), " or ")+")")
+	colorMode = flag.String("color", "auto", "terminal color support ("+strings.Join(termcolor.ModeNames(), ", ")+")")
+)
+
+func main() {
+	retval := 0
+	err := mainWithError()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		retval = -1
+	}
+	os.Exit(retval)
+}
+
+func mainWithError() error {
+	flag.Parse()
+	if *oldSpec == "" {
+		return fmt.Errorf("please provide the old palette (one of %s, or a palette file)", strings.Join(registry.Names(), ", "))
+	}
+	oldPal, err := registry.Load(*oldSpec)
+	if err != nil {
+		return err
+	}
+	if *newSpec == "" {
+		return fmt.Errorf("please provide the new palette (one of %s, or a palette file)", strings.Join(registry.Names(), ", "))
+	}
+	newPal, err := registry.Load(*newSpec)
+	if err != nil {
+		return err
+	}
+
+	report := diff.Compare(oldPal, newPal)
+
+	switch strings.ToLower(*format) {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	case "table":
+		printTable(report)
+		if *grid {
+			var marked [256]bool
+			for i, d := range report.Bytes {
+				marked[i] = d.Changed
+			}
+			mode, err := termcolor.Parse(*colorMode)
+			if err != nil {
+				return err
+			}
+			m, err := contrast.ParseMetric(*metric)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("\n%-48s   %s\n", *oldSpec, *newSpec)
+			tester.Compare(oldPal, newPal, marked, &tester.Options{
+				// Compare gives each palette a Terminal of its own in this mode.
+				Color:  termcolor.New(mode, nil),
+				Metric: m,
+			})
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported format '%s', only 'table' or 'json' are supported", *format)
+	}
+}
+
+func printTable(r *diff.Report) {
+	fmt.Printf("byte  old     new     deltaE\n")
+	for _, d := range r.Bytes {
+		if !d.Changed && !*all {
+			continue
+		}
+		fmt.Printf("%02x    %s  %s  %6.2f\n", d.Byte, d.Old, d.New, d.DeltaE)
+	}
+	fmt.Printf("\n%d of 256 bytes changed (max deltaE %.2f, mean deltaE %.2f)\n", r.ChangedBytes, r.MaxDeltaE, r.MeanDeltaE)
+	fmt.Printf("%d of %d sweep colors have a different nearest byte\n", len(r.NearestChanges), r.SweepSize)
+	for _, c := range r.NearestChanges {
+		fmt.Printf("  %s: %02x -> %02x\n", c.Color, c.Old, c.New)
+	}
+}
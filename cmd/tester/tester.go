@@ -3,19 +3,36 @@ package main
 import (
 	"flag"
 	"fmt"
+	"image/png"
+	"io"
 	"os"
 	"strings"
 
-	"github.com/chrisfenner/bytecolor/pkg/hcl"
-	"github.com/chrisfenner/bytecolor/pkg/hsl"
-	"github.com/chrisfenner/bytecolor/pkg/hsv"
-	"github.com/chrisfenner/bytecolor/pkg/luv"
+	"github.com/chrisfenner/bytecolor/pkg/contrast"
+	"github.com/chrisfenner/bytecolor/pkg/hamming"
+	"github.com/chrisfenner/bytecolor/pkg/quantization"
+	"github.com/chrisfenner/bytecolor/pkg/ramp"
+	"github.com/chrisfenner/bytecolor/pkg/registry"
+	"github.com/chrisfenner/bytecolor/pkg/termcolor"
 	"github.com/chrisfenner/bytecolor/pkg/tester"
-	"github.com/chrisfenner/bytecolor/pkg/windows"
 )
 
 var (
-	palette = flag.String("palette", "hsv", "which color palette to test")
+	palette   = flag.String("palette", "hsv", "which color palette to test")
+	width     = flag.Int("width", 0, "width of the panels in characters (0 means the terminal width, or 80)")
+	height    = flag.Int("height", 0, "height of the panels in lines (0 means the terminal height, or 24)")
+	panels    = flag.String("panels", "", "comma-separated panels to draw, in order (default all, see -list)")
+	list      = flag.Bool("list", false, "list the available panels and exit")
+	minDeltaE = flag.Float64("min-delta-e", hamming.MinDeltaE, "CIEDE2000 difference below which one-bit neighbors are flagged")
+	worst     = flag.Int("worst", tester.DefaultWorst, "number of closest one-bit neighbor pairs to list")
+	metric    = flag.String("contrast-metric", "wcag", "how to measure the contrast of labels ("+strings.Join(contrast.MetricNames(), " or ")+")")
+	minCon    = flag.Float64("min-contrast", 0, "contrast below which text is unreadable (0 means 4.5 for wcag, 60 for apca)")
+	palText   = flag.Bool("palette-text", false, "draw labels in the palette's own colors, rather than black or white")
+	pngOut    = flag.String("png", "", "write the panels to this PNG contact sheet instead of the terminal")
+	htmlOut   = flag.String("html", "", "write the panels to this HTML page instead of the terminal")
+	heatmap   = flag.String("heatmap", "", "write a full-size quantization error heatmap to this PNG and exit")
+	plane     = flag.String("plane", "hsl", "color plane swept by -heatmap (hsl or oklab)")
+	colorMode = flag.String("color", "auto", "terminal color support ("+strings.Join(termcolor.ModeNames(), ", ")+")")
 )
 
 func main() {
@@ -30,41 +47,126 @@ func main() {
 
 func mainWithError() error {
 	flag.Parse()
-	var pal tester.Palette
-	var err error
-	switch strings.ToLower(*palette) {
-	case "hsl":
-		pal, err = hsl.New()
-		if err != nil {
-			return err
+	if *list {
+		for _, info := range tester.RegisteredPanels() {
+			fmt.Printf("%-14s %s\n", info.Name, info.Description)
 		}
-	case "hsv":
-		pal, err = hsv.New()
-		if err != nil {
-			return err
+		return nil
+	}
+	pal, err := registry.New(*palette)
+	if err != nil {
+		return err
+	}
+	m, err := contrast.ParseMetric(*metric)
+	if err != nil {
+		return err
+	}
+	opts := &tester.Options{
+		Width:       *width,
+		Height:      *height,
+		Panels:      panelNames(),
+		MinDeltaE:   *minDeltaE,
+		Worst:       *worst,
+		Metric:      m,
+		MinContrast: *minCon,
+	}
+	if *palText {
+		opts.TextColors = contrast.Colors(pal)
+	}
+
+	if *heatmap != "" {
+		return writeHeatmap(pal)
+	}
+	if *pngOut != "" || *htmlOut != "" {
+		return writeFiles(pal, opts)
+	}
+
+	mode, err := termcolor.Parse(*colorMode)
+	if err != nil {
+		return err
+	}
+	opts.Color = termcolor.New(mode, pal)
+	opts.TerminalSize = true
+
+	if err := tester.Test(pal, opts); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// writeFiles writes the panels to the files given by -png and -html. Unless
+// -width and -height are given, they have the default size, rather than the
+// terminal's, so that they come out the same for everyone.
+func writeFiles(pal registry.Palette, opts *tester.Options) error {
+	drawn, err := tester.Panels(pal, opts)
+	// Write the panels that worked even if some failed.
+	if drawn == nil {
+		return err
+	}
+	heading := fmt.Sprintf("palette %s", *palette)
+	outputs := []struct {
+		path  string
+		write func(io.Writer, string, []*tester.Panel, *tester.Options) error
+	}{
+		{*pngOut, tester.WritePNG},
+		{*htmlOut, tester.WriteHTML},
+	}
+	for _, o := range outputs {
+		if o.path == "" {
+			continue
 		}
-	case "hcl":
-		pal, err = hcl.New()
+		f, err := os.Create(o.path)
 		if err != nil {
 			return err
 		}
-	case "luv":
-		pal, err = luv.New()
-		if err != nil {
+		if err := o.write(f, heading, drawn, opts); err != nil {
+			f.Close()
 			return err
 		}
-	case "win":
-		pal, err = windows.New()
-		if err != nil {
+		if err := f.Close(); err != nil {
 			return err
 		}
-	default:
-		return fmt.Errorf("unsupported palette '%s'", *palette)
+		fmt.Printf("wrote %s.\n", o.path)
+	}
+	return err
+}
+
+// panelNames returns the panels selected by -panels, or nil for all of them.
+func panelNames() []string {
+	if *panels == "" {
+		return nil
 	}
+	return strings.Split(*panels, ",")
+}
 
-	if err := tester.Test(pal); err != nil {
+// writeHeatmap writes the quantization error over a plane of colors to the
+// file given by -heatmap, and lists the regions it covers poorly.
+func writeHeatmap(pal registry.Palette) error {
+	pl, err := quantization.ParsePlane(*plane)
+	if err != nil {
+		return err
+	}
+	m, err := quantization.Sweep(pal, &quantization.Options{Plane: pl})
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(*heatmap)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, m.Image(ramp.Heat, tester.RampDeltaE)); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
 		return err
 	}
 
+	mean, max := m.Stats()
+	fmt.Printf("wrote %s (dE from black at 0 to white at %g: mean %.1f, max %.1f).\n", *heatmap, tester.RampDeltaE, mean, max)
+	for _, r := range m.Poor(tester.PoorDeltaE) {
+		fmt.Printf("  %s\n", r)
+	}
 	return nil
 }
@@ -3,7 +3,6 @@ module github.com/chrisfenner/bytecolor
 go 1.16
 
 require (
-	github.com/lucasb-eyer/go-colorful v1.2.0 // indirect
-	github.com/wayneashleyberry/terminal-dimensions v1.0.0
-	github.com/wayneashleyberry/truecolor v1.0.1 // indirect
+	github.com/lucasb-eyer/go-colorful v1.2.0
+	golang.org/x/term v0.10.0
 )
@@ -0,0 +1,167 @@
+// Package conformance checks palettes against thresholds, so that palette
+// changes can be gated in CI.
+package conformance
+
+import (
+	"encoding/hex"
+	"fmt"
+	"image/color"
+	"io"
+
+	"github.com/chrisfenner/bytecolor/pkg/hamming"
+)
+
+type rgb = [3]byte
+
+type Palette interface {
+	// Select returns 8bpc R,G,B values for a given byte value
+	Select(b byte) rgb
+	// Nearest returns the byte value corresponding to the approximate color
+	Nearest(c color.Color) byte
+}
+
+// Thresholds selects the checks to run and how strict they are.
+type Thresholds struct {
+	// MinHammingDeltaE is the smallest CIEDE2000 difference allowed between
+	// the colors of two bytes that differ in a single bit. 0 skips the check.
+	MinHammingDeltaE float64
+	// RoundTrip requires Nearest(Select(b)) == b for every byte.
+	RoundTrip bool
+	// Unique requires the 256 colors to be different from each other.
+	Unique bool
+	// Conventions requires 0x00 to be black and 0xff to be white.
+	Conventions bool
+}
+
+// Defaults returns the thresholds used when none are given: every check,
+// with one-bit neighbors at least hamming.MinDeltaE apart.
+func Defaults() Thresholds {
+	return Thresholds{
+		MinHammingDeltaE: hamming.MinDeltaE,
+		RoundTrip:        true,
+		Unique:           true,
+		Conventions:      true,
+	}
+}
+
+// Result is the outcome of one check.
+type Result struct {
+	Check    string   `json:"check"`
+	Passed   bool     `json:"passed"`
+	Summary  string   `json:"summary"`
+	Failures []string `json:"failures,omitempty"`
+}
+
+// Report is the outcome of all the checks on one palette.
+type Report struct {
+	Palette string   `json:"palette"`
+	Passed  bool     `json:"passed"`
+	Results []Result `json:"results"`
+}
+
+// Check runs the checks selected by t on p. name identifies p in the report.
+func Check(name string, p Palette, t Thresholds) *Report {
+	r := &Report{Palette: name, Passed: true}
+	add := func(res Result) {
+		res.Passed = len(res.Failures) == 0
+		r.Passed = r.Passed && res.Passed
+		r.Results = append(r.Results, res)
+	}
+	if t.MinHammingDeltaE > 0 {
+		add(neighbors(p, t.MinHammingDeltaE))
+	}
+	if t.RoundTrip {
+		add(roundTrip(p))
+	}
+	if t.Unique {
+		add(unique(p))
+	}
+	if t.Conventions {
+		add(conventions(p))
+	}
+	return r
+}
+
+func hexColor(c rgb) string {
+	return "#" + hex.EncodeToString(c[:])
+}
+
+func neighbors(p Palette, min float64) Result {
+	pairs := hamming.Pairs(p)
+	res := Result{
+		Check:   "hamming",
+		Summary: fmt.Sprintf("closest one-bit neighbors are %s, minimum is %g", pairs[0], min),
+	}
+	for _, pair := range pairs {
+		if pair.DeltaE >= min {
+			break
+		}
+		res.Failures = append(res.Failures, pair.String())
+	}
+	return res
+}
+
+func roundTrip(p Palette) Result {
+	res := Result{Check: "roundtrip"}
+	for i := 0; i < 256; i++ {
+		c := p.Select(byte(i))
+		if got := p.Nearest(color.RGBA{c[0], c[1], c[2], 255}); got != byte(i) {
+			res.Failures = append(res.Failures, fmt.Sprintf("0x%02x (%s): nearest is 0x%02x", i, hexColor(c), got))
+		}
+	}
+	res.Summary = fmt.Sprintf("%d of 256 bytes do not round-trip through Select and Nearest", len(res.Failures))
+	return res
+}
+
+func unique(p Palette) Result {
+	res := Result{Check: "unique"}
+	first := make(map[rgb]int)
+	for i := 0; i < 256; i++ {
+		c := p.Select(byte(i))
+		if j, ok := first[c]; ok {
+			res.Failures = append(res.Failures, fmt.Sprintf("0x%02x has the same color as 0x%02x (%s)", i, j, hexColor(c)))
+			continue
+		}
+		first[c] = i
+	}
+	res.Summary = fmt.Sprintf("%d distinct colors", len(first))
+	return res
+}
+
+func conventions(p Palette) Result {
+	res := Result{Check: "conventions", Summary: "0x00 is black and 0xff is white"}
+	if c := p.Select(0x00); c != (rgb{0, 0, 0}) {
+		res.Failures = append(res.Failures, fmt.Sprintf("0x00 is %s instead of #000000", hexColor(c)))
+	}
+	if c := p.Select(0xff); c != (rgb{255, 255, 255}) {
+		res.Failures = append(res.Failures, fmt.Sprintf("0xff is %s instead of #ffffff", hexColor(c)))
+	}
+	return res
+}
+
+// WriteText writes a readable report to w, listing at most maxFailures
+// failures per check (all of them if maxFailures is 0 or less).
+func (r *Report) WriteText(w io.Writer, maxFailures int) error {
+	status := "PASS"
+	if !r.Passed {
+		status = "FAIL"
+	}
+	if _, err := fmt.Fprintf(w, "%s %s\n", status, r.Palette); err != nil {
+		return err
+	}
+	for _, res := range r.Results {
+		status := "ok  "
+		if !res.Passed {
+			status = "FAIL"
+		}
+		fmt.Fprintf(w, "  %s %-12s %s\n", status, res.Check, res.Summary)
+		for i, f := range res.Failures {
+			if maxFailures > 0 && i == maxFailures {
+				fmt.Fprintf(w, "         ... and %d more\n", len(res.Failures)-i)
+				break
+			}
+			fmt.Fprintf(w, "         %s\n", f)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package conformance
+
+import (
+	"bytes"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/chrisfenner/bytecolor/pkg/registry"
+)
+
+// flat is a palette in which every byte is the same gray.
+type flat struct{}
+
+func (flat) Select(b byte) rgb          { return rgb{0x80, 0x80, 0x80} }
+func (flat) Nearest(c color.Color) byte { return 0 }
+
+func TestCheckPass(t *testing.T) {
+	p, err := registry.New("hcl")
+	if err != nil {
+		t.Fatalf("registry.New() = %v", err)
+	}
+	r := Check("hcl", p, Defaults())
+	if !r.Passed {
+		var buf bytes.Buffer
+		r.WriteText(&buf, 0)
+		t.Fatalf("Check() failed:\n%s", buf.String())
+	}
+	if len(r.Results) != 4 {
+		t.Errorf("Check() ran %d checks, expected 4", len(r.Results))
+	}
+
+	var buf bytes.Buffer
+	if err := r.WriteText(&buf, 0); err != nil {
+		t.Fatalf("WriteText() = %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "PASS hcl\n") || strings.Contains(buf.String(), "FAIL") {
+		t.Errorf("WriteText() = %q, expected only passes", buf.String())
+	}
+}
+
+func TestCheckFail(t *testing.T) {
+	r := Check("flat", flat{}, Defaults())
+	if r.Passed {
+		t.Fatalf("Check() passed a palette with a single color")
+	}
+	failures := map[string]int{
+		// Every one-bit pair, every byte but 0x00, every byte but the
+		// first, and both 0x00 and 0xff.
+		"hamming":     1024,
+		"roundtrip":   255,
+		"unique":      255,
+		"conventions": 2,
+	}
+	for _, res := range r.Results {
+		if res.Passed {
+			t.Errorf("check %s passed", res.Check)
+		}
+		if got, want := len(res.Failures), failures[res.Check]; got != want {
+			t.Errorf("check %s has %d failures, expected %d", res.Check, got, want)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := r.WriteText(&buf, 3); err != nil {
+		t.Fatalf("WriteText() = %v", err)
+	}
+	text := buf.String()
+	for _, want := range []string{"FAIL flat\n", "FAIL hamming", "... and 1021 more", "0xff is #808080 instead of #ffffff"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("WriteText() has no %q:\n%s", want, text)
+		}
+	}
+}
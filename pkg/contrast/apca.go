@@ -0,0 +1,53 @@
+package contrast
+
+import "math"
+
+// APCA constants, from version 0.0.98G-4g of the Accessible Perceptual
+// Contrast Algorithm. See https://github.com/Myndex/apca-w3.
+const (
+	apcaBlackThreshold = 0.022
+	apcaBlackClamp     = 1.414
+	apcaNormalBg       = 0.56
+	apcaNormalText     = 0.57
+	apcaReverseText    = 0.62
+	apcaReverseBg      = 0.65
+	apcaScale          = 1.14
+	apcaOffset         = 0.027
+	apcaLowClip        = 0.1
+	apcaMinDeltaY      = 0.0005
+)
+
+// apcaY returns the screen luminance of a color as APCA estimates it, with
+// very dark colors soft-clamped.
+func apcaY(c rgb) float64 {
+	ch := func(v byte) float64 {
+		return math.Pow(float64(v)/255.0, 2.4)
+	}
+	y := 0.2126729*ch(c[0]) + 0.7151522*ch(c[1]) + 0.0721750*ch(c[2])
+	if y < apcaBlackThreshold {
+		y += math.Pow(apcaBlackThreshold-y, apcaBlackClamp)
+	}
+	return y
+}
+
+// Lc returns the APCA lightness contrast (Lc) of text on bg, from about -108
+// to 106. It is positive for dark text on a light background and negative
+// for light text on a dark one.
+func Lc(text, bg rgb) float64 {
+	yt, yb := apcaY(text), apcaY(bg)
+	if math.Abs(yb-yt) < apcaMinDeltaY {
+		return 0
+	}
+	if yb > yt {
+		sapc := (math.Pow(yb, apcaNormalBg) - math.Pow(yt, apcaNormalText)) * apcaScale
+		if sapc < apcaLowClip {
+			return 0
+		}
+		return (sapc - apcaOffset) * 100
+	}
+	sapc := (math.Pow(yb, apcaReverseBg) - math.Pow(yt, apcaReverseText)) * apcaScale
+	if sapc > -apcaLowClip {
+		return 0
+	}
+	return (sapc + apcaOffset) * 100
+}
@@ -0,0 +1,173 @@
+// Package contrast picks readable text colors for palette backgrounds.
+package contrast
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strings"
+)
+
+type rgb = [3]byte
+
+type Palette interface {
+	// Select returns 8bpc R,G,B values for a given byte value
+	Select(b byte) rgb
+	// Nearest returns the byte value corresponding to the approximate color
+	Nearest(c color.Color) byte
+}
+
+var (
+	// Black and White are the default candidate text colors.
+	Black = rgb{0, 0, 0}
+	White = rgb{255, 255, 255}
+)
+
+// linearize converts an 8-bit sRGB channel to linear light.
+func linearize(c byte) float64 {
+	v := float64(c) / 255.0
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+// Luminance returns the WCAG 2 relative luminance of a color, from 0 to 1.
+// See https://www.w3.org/TR/WCAG21/#dfn-relative-luminance.
+func Luminance(c rgb) float64 {
+	return 0.2126*linearize(c[0]) + 0.7152*linearize(c[1]) + 0.0722*linearize(c[2])
+}
+
+// Ratio returns the WCAG 2 contrast ratio between two colors, from 1 to 21.
+func Ratio(a, b rgb) float64 {
+	la, lb := Luminance(a), Luminance(b)
+	if la < lb {
+		la, lb = lb, la
+	}
+	return (la + 0.05) / (lb + 0.05)
+}
+
+// Metric is a way of measuring the contrast of text on a background.
+type Metric int
+
+const (
+	// WCAG is the WCAG 2 contrast ratio, from 1 to 21.
+	WCAG Metric = iota
+	// APCA is the magnitude of the APCA lightness contrast, from 0 to about
+	// 108. Unlike WCAG, it depends on which color is the text.
+	APCA
+)
+
+var metricNames = []string{"wcag", "apca"}
+
+func (m Metric) String() string {
+	if m < 0 || int(m) >= len(metricNames) {
+		return fmt.Sprintf("Metric(%d)", int(m))
+	}
+	return metricNames[m]
+}
+
+// MetricNames returns the names accepted by ParseMetric.
+func MetricNames() []string {
+	return append([]string(nil), metricNames...)
+}
+
+// ParseMetric returns the metric with the given name, as printed by
+// Metric.String.
+func ParseMetric(name string) (Metric, error) {
+	name = strings.ToLower(name)
+	for i, n := range metricNames {
+		if n == name {
+			return Metric(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unrecognized contrast metric '%s', only %s are supported", name, strings.Join(metricNames, ", "))
+}
+
+// Minimums for body text: WCAG 2 level AA, and APCA's "preferred" level
+// for body text.
+const (
+	MinWCAG = 4.5
+	MinAPCA = 60.0
+)
+
+// Min returns the contrast that body text should reach under m.
+func (m Metric) Min() float64 {
+	if m == APCA {
+		return MinAPCA
+	}
+	return MinWCAG
+}
+
+// Contrast returns the contrast of text on bg. Higher is always better.
+func (m Metric) Contrast(text, bg rgb) float64 {
+	if m == APCA {
+		return math.Abs(Lc(text, bg))
+	}
+	return Ratio(text, bg)
+}
+
+// Format formats a contrast measured by m, such as "4.50:1" or "Lc 60.0".
+func (m Metric) Format(c float64) string {
+	if m == APCA {
+		return fmt.Sprintf("Lc %.1f", c)
+	}
+	return fmt.Sprintf("%.2f:1", c)
+}
+
+// Best returns whichever of the candidate text colors contrasts most with
+// bg, and its contrast. The first candidate wins ties. nil candidates mean
+// black and white.
+func (m Metric) Best(bg rgb, candidates []rgb) (rgb, float64) {
+	if candidates == nil {
+		candidates = []rgb{Black, White}
+	}
+	var best rgb
+	bestContrast := math.Inf(-1)
+	for _, c := range candidates {
+		if v := m.Contrast(c, bg); v > bestContrast {
+			best, bestContrast = c, v
+		}
+	}
+	return best, bestContrast
+}
+
+// Text returns black or white, whichever contrasts more with bg under m.
+func (m Metric) Text(bg rgb) rgb {
+	c, _ := m.Best(bg, nil)
+	return c
+}
+
+// Colors returns the 256 colors of p, in order, as candidate text colors for
+// text that has to be drawn in palette colors.
+func Colors(p Palette) []rgb {
+	result := make([]rgb, 256)
+	for i := range result {
+		result[i] = p.Select(byte(i))
+	}
+	return result
+}
+
+// Failure is a byte whose color no candidate text color is readable on.
+type Failure struct {
+	Byte  byte
+	Color rgb
+	// Text is the candidate with the most contrast, and Contrast is how much.
+	Text     rgb
+	Contrast float64
+}
+
+// Failures returns the bytes of p, in order, on whose colors none of the
+// candidate text colors reaches min contrast under m. nil candidates mean
+// black and white; use Colors(p) for text drawn in the palette itself.
+func (m Metric) Failures(p Palette, min float64, candidates []rgb) []Failure {
+	var result []Failure
+	for i := 0; i < 256; i++ {
+		bg := p.Select(byte(i))
+		text, c := m.Best(bg, candidates)
+		if c < min {
+			result = append(result, Failure{Byte: byte(i), Color: bg, Text: text, Contrast: c})
+		}
+	}
+	return result
+}
@@ -0,0 +1,61 @@
+package contrast
+
+import (
+	"math"
+	"testing"
+)
+
+func TestReferenceValues(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		got      float64
+		expected float64
+	}{
+		{"wcag black on white", Ratio(Black, White), 21},
+		{"wcag gray on white", Ratio(rgb{0x76, 0x76, 0x76}, White), 4.54},
+		{"apca black on white", Lc(Black, White), 106.04},
+		{"apca white on black", Lc(White, Black), -107.88},
+		{"apca gray on white", Lc(rgb{0x88, 0x88, 0x88}, White), 63.06},
+		{"apca same color", Lc(rgb{0x40, 0x80, 0xc0}, rgb{0x40, 0x80, 0xc0}), 0},
+	} {
+		if math.Abs(tc.got-tc.expected) > 0.01 {
+			t.Errorf("%s: got %.4f, expected %.2f", tc.name, tc.got, tc.expected)
+		}
+	}
+}
+
+func TestText(t *testing.T) {
+	for _, m := range []Metric{WCAG, APCA} {
+		if got := m.Text(White); got != Black {
+			t.Errorf("%s: text on white is %v, expected black", m, got)
+		}
+		if got := m.Text(Black); got != White {
+			t.Errorf("%s: text on black is %v, expected white", m, got)
+		}
+	}
+}
+
+func TestMetricsDisagree(t *testing.T) {
+	// APCA finds white text more readable than WCAG 2 does on mid-tones.
+	gray := rgb{0x80, 0x80, 0x80}
+	if got := WCAG.Text(gray); got != Black {
+		t.Errorf("wcag: text on %v is %v, expected black", gray, got)
+	}
+	if got := APCA.Text(gray); got != White {
+		t.Errorf("apca: text on %v is %v, expected white", gray, got)
+	}
+}
+
+func TestBestCandidates(t *testing.T) {
+	bg := rgb{0x80, 0x80, 0x80}
+	candidates := []rgb{{0x70, 0x70, 0x70}, {0x10, 0x20, 0x30}, {0xa0, 0xa0, 0xa0}}
+	for _, m := range []Metric{WCAG, APCA} {
+		got, c := m.Best(bg, candidates)
+		if got != candidates[1] {
+			t.Errorf("%s: best text on %v is %v, expected %v", m, bg, got, candidates[1])
+		}
+		if c != m.Contrast(got, bg) {
+			t.Errorf("%s: best contrast is %v, expected %v", m, c, m.Contrast(got, bg))
+		}
+	}
+}
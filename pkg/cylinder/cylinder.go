@@ -73,13 +73,18 @@ func (p *Palette) Select(val byte) [3]byte {
 	return [3]byte{byte(r / 256), byte(g / 256), byte(b / 256)}
 }
 
+// Distance returns the distance between two colors, as used by Nearest.
+func (p *Palette) Distance(c1, c2 colorful.Color) float64 {
+	return p.dist(c1, c2)
+}
+
 func (p *Palette) Nearest(c color.Color) byte {
 	best := byte(0)
 	bestDist := math.MaxFloat64
 	for i := 0; i < 256; i++ {
 		rgb := p.Select(byte(i))
 		col, _ := colorful.MakeColor(c)
-		dist := p.dist(col, colorful.Color{float64(rgb[0]) / 255.0, float64(rgb[1]) / 255.0, float64(rgb[2]) / 255.0})
+		dist := p.dist(col, colorful.Color{R: float64(rgb[0]) / 255.0, G: float64(rgb[1]) / 255.0, B: float64(rgb[2]) / 255.0})
 		if dist < bestDist {
 			bestDist = dist
 			best = byte(i)
@@ -0,0 +1,26 @@
+package deltae
+
+import "github.com/lucasb-eyer/go-colorful"
+
+type rgb = [3]byte
+
+// Colors returns the CIEDE2000 color difference between two colors, on the
+// conventional scale where 1.0 is roughly a just-noticeable difference.
+func Colors(a, b colorful.Color) float64 {
+	// go-colorful works with L in [0, 1] instead of [0, 100].
+	return 100 * a.DistanceCIEDE2000(b)
+}
+
+// RGB returns the CIEDE2000 color difference between two 8bpc colors.
+func RGB(a, b rgb) float64 {
+	return Colors(FromRGB(a), FromRGB(b))
+}
+
+// FromRGB converts 8bpc R,G,B values to a colorful.Color.
+func FromRGB(c rgb) colorful.Color {
+	return colorful.Color{
+		R: float64(c[0]) / 255.0,
+		G: float64(c[1]) / 255.0,
+		B: float64(c[2]) / 255.0,
+	}
+}
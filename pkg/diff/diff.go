@@ -0,0 +1,104 @@
+// Package diff compares two palettes byte-by-byte.
+package diff
+
+import (
+	"encoding/hex"
+	"image/color"
+
+	"github.com/chrisfenner/bytecolor/pkg/deltae"
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+type rgb = [3]byte
+
+type Palette interface {
+	// Select returns 8bpc R,G,B values for a given byte value
+	Select(b byte) rgb
+	// Nearest returns the byte value corresponding to the approximate color
+	Nearest(c color.Color) byte
+}
+
+// ByteDiff describes how the color of a single byte value changed.
+type ByteDiff struct {
+	Byte    byte    `json:"byte"`
+	Old     string  `json:"old"`
+	New     string  `json:"new"`
+	DeltaE  float64 `json:"deltaE"`
+	Changed bool    `json:"changed"`
+}
+
+// NearestChange describes a sweep color whose nearest byte value changed.
+type NearestChange struct {
+	Color string `json:"color"`
+	Old   byte   `json:"old"`
+	New   byte   `json:"new"`
+}
+
+// Report is the result of comparing two palettes.
+type Report struct {
+	Bytes          [256]ByteDiff   `json:"bytes"`
+	ChangedBytes   int             `json:"changedBytes"`
+	MaxDeltaE      float64         `json:"maxDeltaE"`
+	MeanDeltaE     float64         `json:"meanDeltaE"`
+	SweepSize      int             `json:"sweepSize"`
+	NearestChanges []NearestChange `json:"nearestChanges"`
+}
+
+// Sweep returns the standard set of colors used to compare Nearest results:
+// a ramp of grays followed by a grid of hue, saturation and lightness steps.
+func Sweep() []colorful.Color {
+	var result []colorful.Color
+	for i := 0; i <= 16; i++ {
+		result = append(result, colorful.Hsl(0.0, 0.0, float64(i)/16.0))
+	}
+	for _, s := range []float64{0.5, 1.0} {
+		for l := 1; l < 10; l++ {
+			for h := 0; h < 360; h += 15 {
+				result = append(result, colorful.Hsl(float64(h), s, float64(l)/10.0))
+			}
+		}
+	}
+	return result
+}
+
+// Compare reports the per-byte differences between an old and a new palette,
+// as well as any changes to Nearest over the standard sweep.
+func Compare(old, new Palette) *Report {
+	var r Report
+	total := 0.0
+	for i := 0; i < 256; i++ {
+		o := old.Select(byte(i))
+		n := new.Select(byte(i))
+		d := ByteDiff{
+			Byte:    byte(i),
+			Old:     hex.EncodeToString(o[:]),
+			New:     hex.EncodeToString(n[:]),
+			Changed: o != n,
+		}
+		if d.Changed {
+			d.DeltaE = deltae.RGB(o, n)
+			r.ChangedBytes++
+		}
+		if d.DeltaE > r.MaxDeltaE {
+			r.MaxDeltaE = d.DeltaE
+		}
+		total += d.DeltaE
+		r.Bytes[i] = d
+	}
+	r.MeanDeltaE = total / 256
+
+	sweep := Sweep()
+	r.SweepSize = len(sweep)
+	for _, c := range sweep {
+		o := old.Nearest(c)
+		n := new.Nearest(c)
+		if o != n {
+			r.NearestChanges = append(r.NearestChanges, NearestChange{
+				Color: c.Clamped().Hex(),
+				Old:   o,
+				New:   n,
+			})
+		}
+	}
+	return &r
+}
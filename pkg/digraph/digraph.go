@@ -0,0 +1,117 @@
+// Package digraph plots how often each byte value is followed by each other
+// byte value, which is a quick way to tell instruction sets and text
+// encodings apart.
+package digraph
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"github.com/chrisfenner/bytecolor/pkg/ramp"
+)
+
+type rgb = [3]byte
+
+type Palette interface {
+	// Select returns 8bpc R,G,B values for a given byte value
+	Select(b byte) rgb
+	// Nearest returns the byte value corresponding to the approximate color
+	Nearest(c color.Color) byte
+}
+
+// Counts holds, at [a][b], the number of times byte a is followed by byte b.
+type Counts [256][256]uint64
+
+// Count tallies every pair of consecutive bytes in data.
+func Count(data []byte) *Counts {
+	var c Counts
+	c.Add(data)
+	return &c
+}
+
+// Add tallies every pair of consecutive bytes in data. Pairs spanning
+// separate calls to Add are not counted.
+func (c *Counts) Add(data []byte) {
+	for i := 1; i < len(data); i++ {
+		c[data[i-1]][data[i]]++
+	}
+}
+
+// Max returns the largest count.
+func (c *Counts) Max() uint64 {
+	result := uint64(0)
+	for a := range c {
+		for _, n := range c[a] {
+			if n > result {
+				result = n
+			}
+		}
+	}
+	return result
+}
+
+// Options controls how the plot is drawn. The zero value (or nil) is valid.
+type Options struct {
+	// Scale is the width and height in pixels of each cell. 0 means 1.
+	Scale int
+	// AxisWidth is the thickness in pixels of the axes tinted with the
+	// palette. 0 means 8; a negative value leaves out the axes.
+	AxisWidth int
+	// Ramp colors the cells by count. nil means ramp.Heat.
+	Ramp ramp.Ramp
+}
+
+// Image plots the counts with x as the first byte of each pair and y as the
+// second. Counts are log-scaled so that rare pairs remain visible. The left
+// and top axes are tinted with the palette, so that each row and column
+// carries the color of its byte.
+func Image(c *Counts, p Palette, opts *Options) (*image.RGBA, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	scale := opts.Scale
+	if scale < 0 {
+		return nil, fmt.Errorf("scale must not be negative")
+	}
+	if scale == 0 {
+		scale = 1
+	}
+	axis := opts.AxisWidth
+	if axis == 0 {
+		axis = 8
+	}
+	if axis < 0 {
+		axis = 0
+	}
+	r := opts.Ramp
+	if r == nil {
+		r = ramp.Heat
+	}
+
+	size := 256 * scale
+	m := image.NewRGBA(image.Rect(0, 0, axis+size, axis+size))
+	for i := 0; i < 256; i++ {
+		bg := p.Select(byte(i))
+		tint := image.NewUniform(color.RGBA{bg[0], bg[1], bg[2], 255})
+		// Top axis: column i is the first byte.
+		draw.Draw(m, image.Rect(axis+i*scale, 0, axis+(i+1)*scale, axis), tint, image.Point{}, draw.Src)
+		// Left axis: row i is the second byte.
+		draw.Draw(m, image.Rect(0, axis+i*scale, axis, axis+(i+1)*scale), tint, image.Point{}, draw.Src)
+	}
+
+	logMax := math.Log1p(float64(c.Max()))
+	for a := 0; a < 256; a++ {
+		for b := 0; b < 256; b++ {
+			t := float64(0)
+			if logMax > 0 {
+				t = math.Log1p(float64(c[a][b])) / logMax
+			}
+			cell := image.Rect(axis+a*scale, axis+b*scale, axis+(a+1)*scale, axis+(b+1)*scale)
+			draw.Draw(m, cell, image.NewUniform(r(t)), image.Point{}, draw.Src)
+		}
+	}
+	return m, nil
+}
@@ -0,0 +1,99 @@
+// Package entropy measures the Shannon entropy of binary data.
+package entropy
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+// Max is the entropy in bits per byte of perfectly random data.
+const Max = 8.0
+
+// Shannon returns the entropy of data in bits per byte, from 0 to Max.
+func Shannon(data []byte) float64 {
+	var counts [256]uint64
+	for _, b := range data {
+		counts[b]++
+	}
+	return FromCounts(&counts)
+}
+
+// FromCounts returns the entropy in bits per byte of data in which each byte
+// value b occurs counts[b] times.
+func FromCounts(counts *[256]uint64) float64 {
+	total := uint64(0)
+	for _, c := range counts {
+		total += c
+	}
+	if total == 0 {
+		return 0
+	}
+	result := float64(0)
+	for _, c := range counts {
+		if c == 0 {
+			continue
+		}
+		p := float64(c) / float64(total)
+		result -= p * math.Log2(p)
+	}
+	return result
+}
+
+// Series returns the entropy of a window sliding across data.
+// Element i is the entropy of data[i*step : i*step+window] (truncated at the
+// end of the data), so the byte at offset o is described by element o/step.
+func Series(data []byte, window, step int) ([]float64, error) {
+	if window <= 0 || step <= 0 {
+		return nil, fmt.Errorf("window and step must be positive")
+	}
+	var counts [256]uint64
+	var result []float64
+	// [lo, hi) is the part of the data currently tallied in counts.
+	lo, hi := 0, 0
+	for start := 0; start < len(data); start += step {
+		end := start + window
+		if end > len(data) {
+			end = len(data)
+		}
+		for ; lo < start && lo < hi; lo++ {
+			counts[data[lo]]--
+		}
+		if hi < start {
+			hi, lo = start, start
+		}
+		for ; hi < end; hi++ {
+			counts[data[hi]]++
+		}
+		result = append(result, FromCounts(&counts))
+	}
+	return result, nil
+}
+
+// WriteCSV writes a series returned by Series as CSV with a header row.
+// Each row holds the offset and length of the window and its entropy.
+// base is added to every offset, for series measured from the middle of a file.
+func WriteCSV(w io.Writer, series []float64, base int64, window, step, length int) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"offset", "length", "entropy"}); err != nil {
+		return err
+	}
+	for i, e := range series {
+		offset := i * step
+		n := window
+		if offset+n > length {
+			n = length - offset
+		}
+		if err := cw.Write([]string{
+			strconv.FormatInt(base+int64(offset), 10),
+			strconv.Itoa(n),
+			strconv.FormatFloat(e, 'f', 4, 64),
+		}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
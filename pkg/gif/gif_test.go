@@ -0,0 +1,198 @@
+package gif
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	_ "image/jpeg"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chrisfenner/bytecolor/pkg/hcl"
+	"github.com/chrisfenner/bytecolor/pkg/windows"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+const (
+	// maxDiffs is the number of differences listed when a golden file does
+	// not match.
+	maxDiffs = 20
+	// cropSize is the size of the square from the middle of each test image
+	// that is encoded, to keep the golden files small.
+	cropSize = 64
+)
+
+// TestGoldenEncode encodes part of some test images and compares the decoded
+// palette and pixels with golden files. The encoded bytes themselves are not
+// compared, since they are up to the standard library's LZW encoder.
+func TestGoldenEncode(t *testing.T) {
+	palettes := map[string]func() (Palette, error){
+		"hcl": func() (Palette, error) { return hcl.New() },
+		"win": func() (Palette, error) { return windows.New() },
+	}
+	for _, img := range []string{"flower", "mist"} {
+		for _, name := range []string{"hcl", "win"} {
+			img, name := img, name
+			t.Run(img+"-"+name, func(t *testing.T) {
+				p, err := palettes[name]()
+				if err != nil {
+					t.Fatal(err)
+				}
+				m := readImage(t, filepath.Join("..", "..", "testimages", img+".jpg"))
+				var buf bytes.Buffer
+				if err := Encode(&buf, p, m); err != nil {
+					t.Fatalf("Encode: %v", err)
+				}
+				decoded, err := gif.Decode(&buf)
+				if err != nil {
+					t.Fatalf("decoding output: %v", err)
+				}
+				got := decoded.(*image.Paletted)
+
+				path := filepath.Join("testdata", img+"-"+name+".golden")
+				if *update {
+					if err := writeGolden(path, got); err != nil {
+						t.Fatal(err)
+					}
+					return
+				}
+				want, err := readGolden(path)
+				if err != nil {
+					t.Fatalf("%v (run with -update to create it)", err)
+				}
+				if diffs := describe(want, got); diffs != "" {
+					t.Errorf("output differs from %s (run with -update if intended):\n%s", path, diffs)
+				}
+			})
+		}
+	}
+}
+
+// readImage reads an image and crops the middle cropSize square of it.
+func readImage(t *testing.T, path string) image.Image {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	m, _, err := image.Decode(f)
+	if err != nil {
+		t.Fatalf("decoding %s: %v", path, err)
+	}
+	b := m.Bounds()
+	min := image.Pt(b.Min.X+(b.Dx()-cropSize)/2, b.Min.Y+(b.Dy()-cropSize)/2)
+	crop := image.NewRGBA(image.Rect(0, 0, cropSize, cropSize))
+	draw.Draw(crop, crop.Rect, m, min, draw.Src)
+	return crop
+}
+
+// writeGolden writes the palette of m, one hex color per line, followed by
+// its pixels, one line of hex indices per row.
+func writeGolden(path string, m *image.Paletted) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# palette\n")
+	for _, c := range m.Palette {
+		r, g, b, _ := c.RGBA()
+		fmt.Fprintf(&sb, "%02x%02x%02x\n", r>>8, g>>8, b>>8)
+	}
+	fmt.Fprintf(&sb, "# pixels %dx%d\n", m.Rect.Dx(), m.Rect.Dy())
+	for y := 0; y < m.Rect.Dy(); y++ {
+		row := m.Pix[y*m.Stride : y*m.Stride+m.Rect.Dx()]
+		fmt.Fprintf(&sb, "%s\n", hex.EncodeToString(row))
+	}
+	return ioutil.WriteFile(path, []byte(sb.String()), 0644)
+}
+
+// readGolden reads a file written by writeGolden.
+func readGolden(path string) (*image.Paletted, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var pal color.Palette
+	var rows [][]byte
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		text := scanner.Text()
+		if strings.HasPrefix(text, "#") {
+			continue
+		}
+		b, err := hex.DecodeString(text)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		if len(pal) < 256 {
+			if len(b) != 3 {
+				return nil, fmt.Errorf("%s: '%s' is not a hex RGB value", path, text)
+			}
+			pal = append(pal, color.RGBA{b[0], b[1], b[2], 255})
+			continue
+		}
+		rows = append(rows, b)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%s: no pixels", path)
+	}
+	m := image.NewPaletted(image.Rect(0, 0, len(rows[0]), len(rows)), pal)
+	for y, row := range rows {
+		if len(row) != m.Rect.Dx() {
+			return nil, fmt.Errorf("%s: rows have different lengths", path)
+		}
+		copy(m.Pix[y*m.Stride:], row)
+	}
+	return m, nil
+}
+
+// describe explains how two decoded GIFs differ: by palette entry, then by
+// pixel. It returns "" if they are the same.
+func describe(want, got *image.Paletted) string {
+	if want.Rect.Size() != got.Rect.Size() {
+		return fmt.Sprintf("size: golden %v, got %v", want.Rect.Size(), got.Rect.Size())
+	}
+
+	var diffs []string
+	for i := 0; i < len(want.Palette) || i < len(got.Palette); i++ {
+		var w, g color.Color
+		if i < len(want.Palette) {
+			w = color.RGBAModel.Convert(want.Palette[i])
+		}
+		if i < len(got.Palette) {
+			g = color.RGBAModel.Convert(got.Palette[i])
+		}
+		if w != g {
+			diffs = append(diffs, fmt.Sprintf("palette 0x%02x: golden %v, got %v", i, w, g))
+		}
+	}
+	changed := 0
+	for y := 0; y < want.Rect.Dy(); y++ {
+		for x := 0; x < want.Rect.Dx(); x++ {
+			w, g := want.Pix[y*want.Stride+x], got.Pix[y*got.Stride+x]
+			if w == g {
+				continue
+			}
+			if changed < maxDiffs {
+				diffs = append(diffs, fmt.Sprintf("pixel (%d,%d): golden 0x%02x, got 0x%02x", x, y, w, g))
+			}
+			changed++
+		}
+	}
+	if changed > 0 {
+		diffs = append(diffs, fmt.Sprintf("%d of %d pixels changed", changed, want.Rect.Dx()*want.Rect.Dy()))
+	}
+	return strings.Join(diffs, "\n")
+}
@@ -0,0 +1,118 @@
+// Package glyph draws short labels on images with a tiny built-in 3x5 pixel
+// font, so that rendered files can be annotated without font files.
+package glyph
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+)
+
+const (
+	// Width and Height are the size in pixels of each glyph at scale 1.
+	Width  = 3
+	Height = 5
+	// Advance is the distance in pixels from one glyph to the next at scale 1.
+	Advance = Width + 1
+)
+
+// font holds the glyphs, one string of '#' and '.' per row. Lowercase
+// letters are drawn as uppercase ones, and unknown runes as spaces.
+var font = map[rune][Height]string{
+	'0': {"###", "#.#", "#.#", "#.#", "###"},
+	'1': {".#.", "##.", ".#.", ".#.", "###"},
+	'2': {"###", "..#", "###", "#..", "###"},
+	'3': {"###", "..#", ".##", "..#", "###"},
+	'4': {"#.#", "#.#", "###", "..#", "..#"},
+	'5': {"###", "#..", "###", "..#", "###"},
+	'6': {"###", "#..", "###", "#.#", "###"},
+	'7': {"###", "..#", "..#", ".#.", ".#."},
+	'8': {"###", "#.#", "###", "#.#", "###"},
+	'9': {"###", "#.#", "###", "..#", "###"},
+	'A': {".#.", "#.#", "###", "#.#", "#.#"},
+	'B': {"##.", "#.#", "##.", "#.#", "##."},
+	'C': {".##", "#..", "#..", "#..", ".##"},
+	'D': {"##.", "#.#", "#.#", "#.#", "##."},
+	'E': {"###", "#..", "##.", "#..", "###"},
+	'F': {"###", "#..", "##.", "#..", "#.."},
+	'G': {".##", "#..", "#.#", "#.#", ".##"},
+	'H': {"#.#", "#.#", "###", "#.#", "#.#"},
+	'I': {"###", ".#.", ".#.", ".#.", "###"},
+	'J': {"..#", "..#", "..#", "#.#", ".#."},
+	'K': {"#.#", "#.#", "##.", "#.#", "#.#"},
+	'L': {"#..", "#..", "#..", "#..", "###"},
+	'M': {"#.#", "###", "###", "#.#", "#.#"},
+	'N': {"##.", "#.#", "#.#", "#.#", "#.#"},
+	'O': {".#.", "#.#", "#.#", "#.#", ".#."},
+	'P': {"##.", "#.#", "##.", "#..", "#.."},
+	'Q': {".#.", "#.#", "#.#", "##.", ".##"},
+	'R': {"##.", "#.#", "##.", "#.#", "#.#"},
+	'S': {".##", "#..", ".#.", "..#", "##."},
+	'T': {"###", ".#.", ".#.", ".#.", ".#."},
+	'U': {"#.#", "#.#", "#.#", "#.#", "###"},
+	'V': {"#.#", "#.#", "#.#", "#.#", ".#."},
+	'W': {"#.#", "#.#", "###", "###", "#.#"},
+	'X': {"#.#", "#.#", ".#.", "#.#", "#.#"},
+	'Y': {"#.#", "#.#", ".#.", ".#.", ".#."},
+	'Z': {"###", "..#", ".#.", "#..", "###"},
+	'-': {"...", "...", "###", "...", "..."},
+	'*': {"...", "#.#", ".#.", "#.#", "..."},
+	'+': {"...", ".#.", "###", ".#.", "..."},
+	'.': {"...", "...", "...", "...", ".#."},
+	',': {"...", "...", "...", ".#.", "#.."},
+	':': {"...", ".#.", "...", ".#.", "..."},
+	'=': {"...", "###", "...", "###", "..."},
+	'%': {"#.#", "..#", ".#.", "#..", "#.#"},
+	'/': {"..#", "..#", ".#.", "#..", "#.."},
+	'(': {"..#", ".#.", ".#.", ".#.", "..#"},
+	')': {"#..", ".#.", ".#.", ".#.", "#.."},
+	'<': {"..#", ".#.", "#..", ".#.", "..#"},
+	'>': {"#..", ".#.", "..#", ".#.", "#.."},
+	'_': {"...", "...", "...", "...", "###"},
+	'#': {"#.#", "###", "#.#", "###", "#.#"},
+}
+
+// Has reports whether s can be drawn without losing any runes, which is to
+// say that every rune but space has a glyph.
+func Has(s string) bool {
+	for _, r := range strings.ToUpper(s) {
+		if _, ok := font[r]; !ok && r != ' ' {
+			return false
+		}
+	}
+	return true
+}
+
+// Size returns the width and height in pixels of s drawn at the given scale.
+func Size(s string, scale int) (int, int) {
+	n := len([]rune(s))
+	if n == 0 {
+		return 0, 0
+	}
+	return (n*Advance - 1) * scale, Height * scale
+}
+
+// Draw draws s on m with its top-left corner at (x, y), in color c, with
+// every pixel of the font drawn as a scale x scale square.
+func Draw(m draw.Image, x, y int, s string, c color.Color, scale int) {
+	if scale < 1 {
+		scale = 1
+	}
+	u := image.NewUniform(c)
+	for _, r := range strings.ToUpper(s) {
+		g, ok := font[r]
+		if ok {
+			for row, bits := range g {
+				for col, bit := range bits {
+					if bit != '#' {
+						continue
+					}
+					px := image.Rect(x+col*scale, y+row*scale, x+(col+1)*scale, y+(row+1)*scale)
+					draw.Draw(m, px, u, image.Point{}, draw.Over)
+				}
+			}
+		}
+		x += Advance * scale
+	}
+}
@@ -0,0 +1,119 @@
+// Package halfblock draws images in the terminal with the upper half block
+// character, so that every character cell holds two pixels: the top one in
+// the foreground color and the bottom one in the background color.
+package halfblock
+
+import (
+	"bufio"
+	"image"
+	"image/color"
+	"image/draw"
+	"io"
+	"strings"
+
+	"github.com/chrisfenner/bytecolor/pkg/termcolor"
+)
+
+const (
+	upperHalf = "▀"
+	lowerHalf = "▄"
+)
+
+// Lines returns m as lines of text for t, one line for every two rows of
+// pixels. Transparent pixels are left in the terminal's default colors.
+func Lines(m image.Image, t *termcolor.Terminal) []string {
+	b := m.Bounds()
+	var result []string
+	for y := b.Min.Y; y < b.Max.Y; y += 2 {
+		var sb strings.Builder
+		for x := b.Min.X; x < b.Max.X; x++ {
+			top, topOK := opaque(m, x, y)
+			var bottom color.RGBA
+			bottomOK := false
+			if y+1 < b.Max.Y {
+				bottom, bottomOK = opaque(m, x, y+1)
+			}
+			switch {
+			case topOK && bottomOK:
+				sb.WriteString(t.Sprint(rgb(top), rgb(bottom), upperHalf))
+			case topOK:
+				sb.WriteString(t.SprintFg(rgb(top), upperHalf))
+			case bottomOK:
+				sb.WriteString(t.SprintFg(rgb(bottom), lowerHalf))
+			default:
+				sb.WriteString(" ")
+			}
+		}
+		result = append(result, sb.String())
+	}
+	return result
+}
+
+func rgb(c color.RGBA) [3]byte {
+	return [3]byte{c.R, c.G, c.B}
+}
+
+// opaque returns the color of the pixel at (x, y), and false if it is
+// fully transparent.
+func opaque(m image.Image, x, y int) (color.RGBA, bool) {
+	c := color.RGBAModel.Convert(m.At(x, y)).(color.RGBA)
+	return c, c.A != 0
+}
+
+// Write writes m to w as Lines, each followed by a newline.
+func Write(w io.Writer, m image.Image, t *termcolor.Terminal) error {
+	bw := bufio.NewWriter(w)
+	for _, line := range Lines(m, t) {
+		bw.WriteString(line)
+		bw.WriteString("\n")
+	}
+	return bw.Flush()
+}
+
+// Fit shrinks m, if needed, so that it can be drawn in at most cols x rows
+// character cells, keeping its aspect ratio. Each pixel of the result is the
+// average of the pixels it covers.
+func Fit(m image.Image, cols, rows int) image.Image {
+	b := m.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if cols <= 0 || rows <= 0 || (w <= cols && h <= 2*rows) {
+		return m
+	}
+	// Find the largest size that fits, as a fraction num/den of the original.
+	num, den := cols, w
+	if 2*rows*w < cols*h {
+		num, den = 2*rows, h
+	}
+	dw, dh := w*num/den, h*num/den
+	if dw < 1 {
+		dw = 1
+	}
+	if dh < 1 {
+		dh = 1
+	}
+
+	src := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(src, src.Rect, m, b.Min, draw.Src)
+	result := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		y0, y1 := y*h/dh, (y+1)*h/dh
+		for x := 0; x < dw; x++ {
+			x0, x1 := x*w/dw, (x+1)*w/dw
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					i := src.PixOffset(sx, sy)
+					for c := range sum {
+						sum[c] += int(src.Pix[i+c])
+					}
+				}
+			}
+			n := (y1 - y0) * (x1 - x0)
+			i := result.PixOffset(x, y)
+			for c := range sum {
+				result.Pix[i+c] = uint8(sum[c] / n)
+			}
+		}
+	}
+	return result
+}
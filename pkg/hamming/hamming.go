@@ -0,0 +1,61 @@
+// Package hamming measures how far apart the colors of bytes that differ in
+// a single bit are, which is what lets a palette show bit flips.
+package hamming
+
+import (
+	"fmt"
+	"image/color"
+	"sort"
+
+	"github.com/chrisfenner/bytecolor/pkg/deltae"
+)
+
+type rgb = [3]byte
+
+type Palette interface {
+	// Select returns 8bpc R,G,B values for a given byte value
+	Select(b byte) rgb
+	// Nearest returns the byte value corresponding to the approximate color
+	Nearest(c color.Color) byte
+}
+
+// MinDeltaE is the CIEDE2000 difference below which the colors of one-bit
+// neighbors are too close to tell apart at a glance.
+const MinDeltaE = 3.0
+
+// Pair is two bytes that differ in a single bit.
+type Pair struct {
+	A, B byte
+	// Bit is the index of the bit that differs, 0 being the least significant.
+	Bit int
+	// DeltaE is the CIEDE2000 difference between the colors of A and B.
+	DeltaE float64
+}
+
+func (n Pair) String() string {
+	return fmt.Sprintf("0x%02x-0x%02x (bit %d): dE %.2f", n.A, n.B, n.Bit, n.DeltaE)
+}
+
+// Pairs returns all 1024 pairs of bytes that differ in a single bit, with
+// A < B, sorted from the closest colors to the farthest.
+func Pairs(p Palette) []Pair {
+	var result []Pair
+	for a := 0; a < 256; a++ {
+		for bit := 0; bit < 8; bit++ {
+			b := a ^ (1 << bit)
+			if b < a {
+				continue
+			}
+			result = append(result, Pair{
+				A:      byte(a),
+				B:      byte(b),
+				Bit:    bit,
+				DeltaE: deltae.RGB(p.Select(byte(a)), p.Select(byte(b))),
+			})
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].DeltaE < result[j].DeltaE
+	})
+	return result
+}
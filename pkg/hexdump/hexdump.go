@@ -0,0 +1,151 @@
+// Package hexdump prints xxd-style dumps in which every byte is drawn on its
+// palette color.
+package hexdump
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"image/color"
+	"io"
+	"strings"
+
+	"github.com/chrisfenner/bytecolor/pkg/contrast"
+	"github.com/chrisfenner/bytecolor/pkg/termcolor"
+)
+
+type rgb = [3]byte
+
+type Palette interface {
+	// Select returns 8bpc R,G,B values for a given byte value
+	Select(b byte) rgb
+	// Nearest returns the byte value corresponding to the approximate color
+	Nearest(c color.Color) byte
+}
+
+// Options controls the layout of the dump. The zero value (or nil) gives
+// xxd's defaults.
+type Options struct {
+	// Columns is the number of bytes per line. 0 means 16.
+	Columns int
+	// Group is the number of bytes per group of hex digits. 0 means 2.
+	Group int
+	// Highlight, if not nil, reports the addresses of bytes to draw in
+	// reverse video (the palette color on a contrasting background).
+	Highlight func(address int64) bool
+	// Color formats the colors for the terminal. nil means truecolor.
+	Color *termcolor.Terminal
+	// Metric measures the contrast of text on the palette colors. Bytes are
+	// drawn in whichever of TextColors contrasts most under it.
+	Metric contrast.Metric
+	// TextColors are the candidate text colors. nil means black and white.
+	TextColors []rgb
+}
+
+func (o *Options) columns() int {
+	if o == nil || o.Columns <= 0 {
+		return 16
+	}
+	return o.Columns
+}
+
+func (o *Options) group() int {
+	if o == nil || o.Group <= 0 {
+		return 2
+	}
+	return o.Group
+}
+
+// Printable returns the character shown for b in the ASCII column.
+func Printable(b byte) byte {
+	if b >= 0x20 && b < 0x7f {
+		return b
+	}
+	return '.'
+}
+
+// Cell returns s drawn with the palette color of b as background and the
+// text color from opts that contrasts most with it as foreground.
+func (o *Options) Cell(p Palette, b byte, s string) string {
+	bg := p.Select(b)
+	return o.color().Sprint(o.text(bg), bg, s)
+}
+
+// Highlighted returns s drawn in reverse video compared to Cell. Without
+// color, it is plain reverse video.
+func (o *Options) Highlighted(p Palette, b byte, s string) string {
+	if o.color().Mode() == termcolor.None {
+		return "\x1b[7m" + s + "\x1b[27m"
+	}
+	fg := p.Select(b)
+	return o.color().Sprint(fg, o.text(fg), s)
+}
+
+func (o *Options) color() *termcolor.Terminal {
+	if o == nil {
+		return nil
+	}
+	return o.Color
+}
+
+// text returns the text color for bg.
+func (o *Options) text(bg rgb) rgb {
+	if o == nil {
+		return contrast.WCAG.Text(bg)
+	}
+	c, _ := o.Metric.Best(bg, o.TextColors)
+	return c
+}
+
+func (o *Options) cell(p Palette, b byte, address int64, s string) string {
+	if o != nil && o.Highlight != nil && o.Highlight(address) {
+		return o.Highlighted(p, b, s)
+	}
+	return o.Cell(p, b, s)
+}
+
+// Line formats one line of the dump for the bytes in data, which start at
+// the given address. data may be shorter than a full line.
+func Line(data []byte, address int64, p Palette, opts *Options) string {
+	columns := opts.columns()
+	group := opts.group()
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%08x: ", address)
+	for i := 0; i < columns; i++ {
+		if i < len(data) {
+			sb.WriteString(opts.cell(p, data[i], address+int64(i), hex.EncodeToString(data[i:i+1])))
+		} else {
+			sb.WriteString("  ")
+		}
+		if (i+1)%group == 0 {
+			sb.WriteString(" ")
+		}
+	}
+	sb.WriteString(" ")
+	for i, b := range data {
+		sb.WriteString(opts.cell(p, b, address+int64(i), string([]byte{Printable(b)})))
+	}
+	return sb.String()
+}
+
+// Dump reads r to the end and writes a line to w for every opts.Columns
+// bytes. Addresses start from address.
+func Dump(w io.Writer, r io.Reader, address int64, p Palette, opts *Options) error {
+	bw := bufio.NewWriter(w)
+	buf := make([]byte, opts.columns())
+	for {
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			fmt.Fprintln(bw, Line(buf[:n], address, p, opts))
+			address += int64(n)
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			bw.Flush()
+			return err
+		}
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,83 @@
+package layout
+
+import "image"
+
+// Hilbert lays bytes out along a Hilbert curve, so that bytes which are close
+// together in the file are also close together on the canvas.
+// The canvas is the smallest power-of-two square that holds the whole file;
+// positions past the end of the file are padding.
+type Hilbert struct {
+	side   int
+	length int64
+}
+
+// NewHilbert returns a Hilbert layout for a file of the given length.
+func NewHilbert(length int64) *Hilbert {
+	side := 1
+	for int64(side)*int64(side) < length {
+		side <<= 1
+	}
+	return &Hilbert{
+		side:   side,
+		length: length,
+	}
+}
+
+// Side returns the width (and height) of the canvas.
+func (h *Hilbert) Side() int {
+	return h.side
+}
+
+func (h *Hilbert) Bounds() image.Rectangle {
+	return image.Rect(0, 0, h.side, h.side)
+}
+
+// Point converts a distance along the curve to (x, y).
+// See https://en.wikipedia.org/wiki/Hilbert_curve for the algorithm.
+func (h *Hilbert) Point(offset int64) (int, int) {
+	x, y := int64(0), int64(0)
+	t := offset
+	for s := int64(1); s < int64(h.side); s <<= 1 {
+		rx := 1 & (t / 2)
+		ry := 1 & (t ^ rx)
+		x, y = rotate(s, x, y, rx, ry)
+		x += s * rx
+		y += s * ry
+		t /= 4
+	}
+	return int(x), int(y)
+}
+
+// Offset converts (x, y) to a distance along the curve.
+func (h *Hilbert) Offset(x, y int) (int64, bool) {
+	if x < 0 || x >= h.side || y < 0 || y >= h.side {
+		return 0, false
+	}
+	px, py := int64(x), int64(y)
+	d := int64(0)
+	for s := int64(h.side) / 2; s > 0; s /= 2 {
+		rx := int64(0)
+		if px&s != 0 {
+			rx = 1
+		}
+		ry := int64(0)
+		if py&s != 0 {
+			ry = 1
+		}
+		d += s * s * ((3 * rx) ^ ry)
+		px, py = rotate(int64(h.side), px, py, rx, ry)
+	}
+	return d, d < h.length
+}
+
+// rotate flips a quadrant so that the sub-curve within it is oriented correctly.
+func rotate(n, x, y, rx, ry int64) (int64, int64) {
+	if ry == 0 {
+		if rx == 1 {
+			x = n - 1 - x
+			y = n - 1 - y
+		}
+		x, y = y, x
+	}
+	return x, y
+}
@@ -0,0 +1,99 @@
+// Package layout maps byte offsets in a file to pixel positions in an image.
+package layout
+
+import (
+	"fmt"
+	"image"
+	"strings"
+)
+
+// Layout is a two-way mapping between file offsets and (x, y) positions.
+type Layout interface {
+	// Bounds returns the size of the canvas needed to hold every offset.
+	Bounds() image.Rectangle
+	// Point returns the position of the byte at the given offset.
+	Point(offset int64) (x, y int)
+	// Offset returns the offset of the byte drawn at (x, y), or false if
+	// (x, y) is outside the canvas or does not correspond to any offset.
+	Offset(x, y int) (int64, bool)
+}
+
+// RowMajor lays bytes out left to right, top to bottom.
+type RowMajor struct {
+	// Width is the number of bytes per row.
+	Width int
+	// Length is the number of bytes in the file.
+	Length int64
+}
+
+func (l RowMajor) Bounds() image.Rectangle {
+	height := (l.Length + int64(l.Width) - 1) / int64(l.Width)
+	return image.Rect(0, 0, l.Width, int(height))
+}
+
+func (l RowMajor) Point(offset int64) (int, int) {
+	return int(offset % int64(l.Width)), int(offset / int64(l.Width))
+}
+
+func (l RowMajor) Offset(x, y int) (int64, bool) {
+	if x < 0 || x >= l.Width || y < 0 {
+		return 0, false
+	}
+	offset := int64(y)*int64(l.Width) + int64(x)
+	return offset, offset < l.Length
+}
+
+// Constructor returns a layout for a file of the given length.
+type Constructor = func(length int64) Layout
+
+// Params holds the parameters of the layouts that have any.
+type Params struct {
+	// Width is the number of bytes per row of row-based layouts.
+	Width int
+	// RecordSize is the size of a record for the column-major layout.
+	RecordSize int
+	// Height is the number of records per band for the column-major layout.
+	Height int
+}
+
+// Names returns the names of the layouts known to ByName.
+func Names() []string {
+	return []string{"rowmajor", "boustrophedon", "columnmajor", "hilbert", "zorder"}
+}
+
+// ByName returns a constructor for the named layout.
+func ByName(name string, params Params) (Constructor, error) {
+	switch strings.ToLower(name) {
+	case "rowmajor", "":
+		if params.Width <= 0 {
+			return nil, fmt.Errorf("the %s layout needs a positive width", name)
+		}
+		return func(length int64) Layout {
+			return RowMajor{Width: params.Width, Length: length}
+		}, nil
+	case "boustrophedon":
+		if params.Width <= 0 {
+			return nil, fmt.Errorf("the %s layout needs a positive width", name)
+		}
+		return func(length int64) Layout {
+			return Boustrophedon{Width: params.Width, Length: length}
+		}, nil
+	case "columnmajor":
+		if params.RecordSize <= 0 || params.Height <= 0 {
+			return nil, fmt.Errorf("the %s layout needs a positive record size and height", name)
+		}
+		return func(length int64) Layout {
+			return ColumnMajor{RecordSize: params.RecordSize, Height: params.Height, Length: length}
+		}, nil
+	case "hilbert":
+		return func(length int64) Layout {
+			return NewHilbert(length)
+		}, nil
+	case "zorder":
+		return func(length int64) Layout {
+			return NewZOrder(length)
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported layout '%s', only %s are supported", name, strings.Join(Names(), ", "))
+	}
+}
@@ -0,0 +1,71 @@
+package layout
+
+import "image"
+
+// Boustrophedon lays bytes out in serpentine rows: left to right on even rows
+// and right to left on odd rows, so that consecutive bytes are always adjacent.
+type Boustrophedon struct {
+	// Width is the number of bytes per row.
+	Width int
+	// Length is the number of bytes in the file.
+	Length int64
+}
+
+func (l Boustrophedon) Bounds() image.Rectangle {
+	return RowMajor(l).Bounds()
+}
+
+func (l Boustrophedon) Point(offset int64) (int, int) {
+	x, y := RowMajor(l).Point(offset)
+	if y%2 == 1 {
+		x = l.Width - 1 - x
+	}
+	return x, y
+}
+
+func (l Boustrophedon) Offset(x, y int) (int64, bool) {
+	if y%2 == 1 {
+		x = l.Width - 1 - x
+	}
+	return RowMajor(l).Offset(x, y)
+}
+
+// ColumnMajor lays fixed-size records out top to bottom, in bands that are
+// one record wide, moving to the next band to the right once a band is full.
+// Each byte of a record always lands in the same column of its band, so
+// arrays of fixed-width structures show up as vertical stripes.
+type ColumnMajor struct {
+	// RecordSize is the number of bytes per record, and the width of a band.
+	RecordSize int
+	// Height is the number of records per band.
+	Height int
+	// Length is the number of bytes in the file.
+	Length int64
+}
+
+func (l ColumnMajor) Bounds() image.Rectangle {
+	records := (l.Length + int64(l.RecordSize) - 1) / int64(l.RecordSize)
+	height := int64(l.Height)
+	if records < height {
+		height = records
+	}
+	bands := (records + int64(l.Height) - 1) / int64(l.Height)
+	return image.Rect(0, 0, int(bands)*l.RecordSize, int(height))
+}
+
+func (l ColumnMajor) Point(offset int64) (int, int) {
+	record := offset / int64(l.RecordSize)
+	band := record / int64(l.Height)
+	x := band*int64(l.RecordSize) + offset%int64(l.RecordSize)
+	return int(x), int(record % int64(l.Height))
+}
+
+func (l ColumnMajor) Offset(x, y int) (int64, bool) {
+	if x < 0 || y < 0 || y >= l.Height {
+		return 0, false
+	}
+	band := int64(x / l.RecordSize)
+	record := band*int64(l.Height) + int64(y)
+	offset := record*int64(l.RecordSize) + int64(x%l.RecordSize)
+	return offset, offset < l.Length
+}
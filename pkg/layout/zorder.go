@@ -0,0 +1,56 @@
+package layout
+
+import "image"
+
+// ZOrder lays bytes out along a Morton (Z-order) curve, so that every aligned
+// block of 4^n bytes in the file fills an aligned 2^n square on the canvas.
+// The canvas is the smallest power-of-two square that holds the whole file;
+// positions past the end of the file are padding.
+type ZOrder struct {
+	side   int
+	length int64
+}
+
+// NewZOrder returns a Z-order layout for a file of the given length.
+func NewZOrder(length int64) *ZOrder {
+	side := 1
+	for int64(side)*int64(side) < length {
+		side <<= 1
+	}
+	return &ZOrder{
+		side:   side,
+		length: length,
+	}
+}
+
+// Side returns the width (and height) of the canvas.
+func (z *ZOrder) Side() int {
+	return z.side
+}
+
+func (z *ZOrder) Bounds() image.Rectangle {
+	return image.Rect(0, 0, z.side, z.side)
+}
+
+// Point de-interleaves the bits of the offset: even bits form x and odd bits form y.
+func (z *ZOrder) Point(offset int64) (int, int) {
+	x, y := 0, 0
+	for bit := 0; offset>>(2*bit) != 0; bit++ {
+		x |= int((offset>>(2*bit))&1) << bit
+		y |= int((offset>>(2*bit+1))&1) << bit
+	}
+	return x, y
+}
+
+// Offset interleaves the bits of x and y.
+func (z *ZOrder) Offset(x, y int) (int64, bool) {
+	if x < 0 || x >= z.side || y < 0 || y >= z.side {
+		return 0, false
+	}
+	offset := int64(0)
+	for bit := 0; x>>bit != 0 || y>>bit != 0; bit++ {
+		offset |= int64((x>>bit)&1) << (2 * bit)
+		offset |= int64((y>>bit)&1) << (2*bit + 1)
+	}
+	return offset, offset < z.length
+}
@@ -0,0 +1,219 @@
+// Package palettetest is a standard test suite for implementations of the
+// Select/Nearest palette interface. Palette authors call Run from a test:
+//
+//	func TestPalette(t *testing.T) {
+//		p, err := mypalette.New()
+//		if err != nil {
+//			t.Fatal(err)
+//		}
+//		palettetest.Run(t, p)
+//	}
+//
+// Run with -race to check that the palette is safe for concurrent use.
+package palettetest
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/chrisfenner/bytecolor/pkg/conformance"
+	"github.com/chrisfenner/bytecolor/pkg/deltae"
+	"github.com/chrisfenner/bytecolor/pkg/diff"
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+type rgb = [3]byte
+
+type Palette interface {
+	// Select returns 8bpc R,G,B values for a given byte value
+	Select(b byte) rgb
+	// Nearest returns the byte value corresponding to the approximate color
+	Nearest(c color.Color) byte
+}
+
+// goroutines is the number of goroutines that use the palette at once in
+// the concurrency test.
+const goroutines = 8
+
+// Run tests p as subtests of t, then logs its quality metrics.
+func Run(t *testing.T, p Palette) {
+	t.Helper()
+	t.Run("Deterministic", func(t *testing.T) { testDeterministic(t, p) })
+	t.Run("Concurrent", func(t *testing.T) { testConcurrent(t, p) })
+	t.Run("NearestExact", func(t *testing.T) { testNearestExact(t, p) })
+	t.Run("RoundTrip", func(t *testing.T) { testRoundTrip(t, p) })
+	t.Run("Range", func(t *testing.T) { testRange(t, p) })
+	logMetrics(t, p)
+}
+
+// colors returns the 256 colors of p.
+func colors(p Palette) [256]rgb {
+	var result [256]rgb
+	for i := range result {
+		result[i] = p.Select(byte(i))
+	}
+	return result
+}
+
+// sweep returns diff.Sweep as RGBA colors.
+func sweep() []color.RGBA {
+	var result []color.RGBA
+	for _, c := range diff.Sweep() {
+		r, g, b := c.Clamped().RGB255()
+		result = append(result, color.RGBA{r, g, b, 255})
+	}
+	return result
+}
+
+func testDeterministic(t *testing.T, p Palette) {
+	first := colors(p)
+	if second := colors(p); first != second {
+		for i := range first {
+			if first[i] != second[i] {
+				t.Errorf("Select(0x%02x) returned %v, then %v", i, first[i], second[i])
+			}
+		}
+	}
+	for _, c := range sweep() {
+		if a, b := p.Nearest(c), p.Nearest(c); a != b {
+			t.Errorf("Nearest(%v) returned 0x%02x, then 0x%02x", c, a, b)
+		}
+	}
+}
+
+func testConcurrent(t *testing.T, p Palette) {
+	want := colors(p)
+	colors := sweep()
+	nearest := make([]byte, len(colors))
+	for i, c := range colors {
+		nearest[i] = p.Nearest(c)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan string, goroutines)
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 256; i++ {
+				// Start each goroutine at a different byte.
+				b := byte(i + g*32)
+				if got := p.Select(b); got != want[b] {
+					errs <- fmt.Sprintf("concurrent Select(0x%02x) returned %v, want %v", b, got, want[b])
+					return
+				}
+			}
+			for i := g; i < len(colors); i += goroutines {
+				if got := p.Nearest(colors[i]); got != nearest[i] {
+					errs <- fmt.Sprintf("concurrent Nearest(%v) returned 0x%02x, want 0x%02x", colors[i], got, nearest[i])
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+// testNearestExact checks that Nearest finds exact palette colors, however
+// they are represented.
+func testNearestExact(t *testing.T, p Palette) {
+	all := colors(p)
+	for i, c := range all {
+		for _, exact := range []color.Color{
+			color.RGBA{c[0], c[1], c[2], 255},
+			color.NRGBA{c[0], c[1], c[2], 255},
+			color.RGBA64{uint16(c[0]) * 0x101, uint16(c[1]) * 0x101, uint16(c[2]) * 0x101, 0xffff},
+			deltae.FromRGB(c),
+		} {
+			if got := p.Nearest(exact); all[got] != c {
+				t.Errorf("Nearest(%#v), the color of 0x%02x, returned 0x%02x, whose color is %v", exact, i, got, all[got])
+			}
+		}
+	}
+}
+
+// testRoundTrip checks that Nearest(Select(b)) == b for every byte whose
+// color is unique. For duplicate colors, any byte with that color will do
+// (see testNearestExact).
+func testRoundTrip(t *testing.T, p Palette) {
+	all := colors(p)
+	count := make(map[rgb]int)
+	for _, c := range all {
+		count[c]++
+	}
+	for i, c := range all {
+		if count[c] > 1 {
+			continue
+		}
+		if got := p.Nearest(color.RGBA{c[0], c[1], c[2], 255}); got != byte(i) {
+			t.Errorf("Nearest(Select(0x%02x)) = 0x%02x", i, got)
+		}
+	}
+}
+
+// testRange checks that Nearest returns a byte for colors at and beyond the
+// edges of the color space without panicking, and that black and white map
+// to the darkest and lightest colors' neighborhoods.
+func testRange(t *testing.T, p Palette) {
+	for _, c := range []color.Color{
+		color.Black,
+		color.White,
+		color.Transparent,
+		color.Gray{128},
+		color.Gray16{0x8000},
+		color.NRGBA{255, 0, 0, 128},
+		colorful.Color{R: 1.5, G: -0.5, B: 0.5},
+	} {
+		b := p.Nearest(c)
+		t.Logf("Nearest(%#v) = 0x%02x (%v)", c, b, p.Select(b))
+	}
+	all := colors(p)
+	darkest, lightest := 0, 0
+	for i, c := range all {
+		if lum(c) < lum(all[darkest]) {
+			darkest = i
+		}
+		if lum(c) > lum(all[lightest]) {
+			lightest = i
+		}
+	}
+	if got := p.Nearest(color.Black); lum(all[got]) > lum(all[darkest])+0.25 {
+		t.Errorf("Nearest(black) = 0x%02x (%v), far lighter than the darkest color 0x%02x (%v)", got, all[got], darkest, all[darkest])
+	}
+	if got := p.Nearest(color.White); lum(all[got]) < lum(all[lightest])-0.25 {
+		t.Errorf("Nearest(white) = 0x%02x (%v), far darker than the lightest color 0x%02x (%v)", got, all[got], lightest, all[lightest])
+	}
+}
+
+// lum returns the CIE L* lightness of c, from 0 to 1.
+func lum(c rgb) float64 {
+	l, _, _ := deltae.FromRGB(c).Lab()
+	return l
+}
+
+// logMetrics logs how well p does on the conformance checks and how well it
+// covers a sweep of colors. They are informational and never fail the test.
+func logMetrics(t *testing.T, p Palette) {
+	t.Helper()
+	var sb strings.Builder
+	conformance.Check(t.Name(), p, conformance.Defaults()).WriteText(&sb, 5)
+	t.Logf("conformance (informational):\n%s", sb.String())
+
+	var sum, max float64
+	colors := diff.Sweep()
+	for _, c := range colors {
+		d := deltae.Colors(c.Clamped(), deltae.FromRGB(p.Select(p.Nearest(c.Clamped()))))
+		sum += d
+		if d > max {
+			max = d
+		}
+	}
+	t.Logf("sweep of %d colors: mean dE %.2f, max dE %.2f from their nearest palette colors", len(colors), sum/float64(len(colors)), max)
+}
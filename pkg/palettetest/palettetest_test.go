@@ -0,0 +1,20 @@
+package palettetest_test
+
+import (
+	"testing"
+
+	"github.com/chrisfenner/bytecolor/pkg/palettetest"
+	"github.com/chrisfenner/bytecolor/pkg/registry"
+)
+
+func TestBuiltins(t *testing.T) {
+	for _, name := range registry.Names() {
+		t.Run(name, func(t *testing.T) {
+			p, err := registry.New(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			palettetest.Run(t, p)
+		})
+	}
+}
@@ -1,34 +1,173 @@
 package polar
 
-import "math"
+import (
+	"errors"
+	"math"
+)
+
+// Epsilon is the radius below which a resultant vector is treated as zero.
+// Summing exactly opposing coordinates rarely cancels out to exactly 0.0.
+const Epsilon = 1e-9
+
+// ErrZeroResultant is returned when an angle is requested of a vector that
+// has (nearly) zero length, and so has no meaningful direction.
+var ErrZeroResultant = errors.New("polar: resultant vector has zero length, angle is undefined")
 
 type Coord struct {
 	Degrees float64
 	Radius  float64
 }
 
+// Normalize returns the equivalent angle in [0, 360).
+func Normalize(degrees float64) float64 {
+	degrees = math.Mod(degrees, 360.0)
+	if degrees < 0.0 {
+		degrees += 360.0
+	}
+	// -tiny + 360.0 can round up to exactly 360.0
+	if degrees >= 360.0 {
+		degrees = 0.0
+	}
+	return degrees
+}
+
+func toXY(coord Coord) (float64, float64) {
+	rads := coord.Degrees * math.Pi / 180.0
+	return coord.Radius * math.Cos(rads), coord.Radius * math.Sin(rads)
+}
+
+func fromXY(x, y float64) Coord {
+	rads := math.Atan2(y, x)
+	return Coord{
+		Degrees: Normalize(rads * 180.0 / math.Pi),
+		Radius:  math.Sqrt(y*y + x*x),
+	}
+}
+
+// Add returns the vector sum of the coordinates.
+// If the sum is shorter than Epsilon it is reported as exactly {0, 0}; use Mean
+// when that case needs to be told apart from a genuine 0 degree result.
 func Add(coords ...Coord) Coord {
 	resultX := float64(0)
 	resultY := float64(0)
 
 	for _, coord := range coords {
-		rads := coord.Degrees * math.Pi / 180.0
-		resultX += (coord.Radius * math.Cos(rads))
-		resultY += (coord.Radius * math.Sin(rads))
+		x, y := toXY(coord)
+		resultX += x
+		resultY += y
 	}
 
-	rads := math.Atan2(resultY, resultX)
-	resultR := math.Sqrt(resultY*resultY + resultX*resultX)
-	// Normalize the angle to [0, 360)
-	degrees := rads * 180.0 / math.Pi
-	for degrees < 0.0 {
-		degrees += 360.0
+	result := fromXY(resultX, resultY)
+	if result.Radius < Epsilon {
+		return Coord{}
 	}
-	for degrees >= 360.0 {
-		degrees -= 360.0
+	return result
+}
+
+// Sub returns the vector difference a - b.
+func Sub(a, b Coord) Coord {
+	return Add(a, Scale(b, -1))
+}
+
+// Scale multiplies the length of the vector by k.
+// A negative k points the result in the opposite direction.
+func Scale(c Coord, k float64) Coord {
+	if k < 0 {
+		return Coord{
+			Degrees: Normalize(c.Degrees + 180.0),
+			Radius:  -k * c.Radius,
+		}
+	}
+	return Coord{
+		Degrees: Normalize(c.Degrees),
+		Radius:  k * c.Radius,
+	}
+}
+
+// Mean returns the average of the coordinates as vectors.
+// It returns ErrZeroResultant if the coordinates cancel each other out.
+func Mean(coords ...Coord) (Coord, error) {
+	weights := make([]float64, len(coords))
+	for i := range weights {
+		weights[i] = 1.0
+	}
+	return WeightedMean(coords, weights)
+}
+
+// WeightedMean returns the weighted average of the coordinates as vectors.
+// It returns ErrZeroResultant if the weighted coordinates cancel each other
+// out, or if the weights sum to zero.
+func WeightedMean(coords []Coord, weights []float64) (Coord, error) {
+	if len(coords) != len(weights) {
+		return Coord{}, errors.New("polar: need exactly one weight per coordinate")
+	}
+	resultX := float64(0)
+	resultY := float64(0)
+	totalWeight := float64(0)
+	for i, coord := range coords {
+		x, y := toXY(coord)
+		resultX += weights[i] * x
+		resultY += weights[i] * y
+		totalWeight += weights[i]
+	}
+	if math.Abs(totalWeight) < Epsilon {
+		return Coord{}, ErrZeroResultant
+	}
+	result := fromXY(resultX/totalWeight, resultY/totalWeight)
+	if result.Radius < Epsilon {
+		return Coord{}, ErrZeroResultant
+	}
+	return result, nil
+}
+
+// Variance returns the circular variance of the angles of the coordinates,
+// using each radius as the weight of its angle. The result is in [0, 1]:
+// 0 when all the angles agree, and 1 when they cancel each other out.
+// It returns ErrZeroResultant if the radii sum to zero.
+func Variance(coords ...Coord) (float64, error) {
+	resultX := float64(0)
+	resultY := float64(0)
+	totalWeight := float64(0)
+	for _, coord := range coords {
+		x, y := toXY(Coord{Degrees: coord.Degrees, Radius: 1.0})
+		resultX += coord.Radius * x
+		resultY += coord.Radius * y
+		totalWeight += math.Abs(coord.Radius)
+	}
+	if totalWeight < Epsilon {
+		return 0, ErrZeroResultant
+	}
+	r := math.Sqrt(resultX*resultX+resultY*resultY) / totalWeight
+	return math.Max(0, math.Min(1, 1-r)), nil
+}
+
+// Arc returns the signed shortest angular distance from a to b in degrees,
+// in [-180, 180). Exactly opposite angles are reported as -180.
+func Arc(a, b float64) float64 {
+	return Normalize(b-a+180.0) - 180.0
+}
+
+// LerpDegrees interpolates between two angles along the shortest arc.
+// t = 0 gives a and t = 1 gives b (both normalized to [0, 360)).
+func LerpDegrees(a, b, t float64) float64 {
+	return Normalize(a + t*Arc(a, b))
+}
+
+// Lerp interpolates between two coordinates, moving the angle along the
+// shortest arc and the radius linearly. If one of the coordinates has zero
+// radius its angle is meaningless, so the angle of the other is used.
+func Lerp(a, b Coord, t float64) Coord {
+	radius := a.Radius + t*(b.Radius-a.Radius)
+	switch {
+	case a.Radius < Epsilon && b.Radius < Epsilon:
+		return Coord{Degrees: 0, Radius: radius}
+	case a.Radius < Epsilon:
+		return Coord{Degrees: Normalize(b.Degrees), Radius: radius}
+	case b.Radius < Epsilon:
+		return Coord{Degrees: Normalize(a.Degrees), Radius: radius}
 	}
 	return Coord{
-		Degrees: degrees,
-		Radius:  resultR,
+		Degrees: LerpDegrees(a.Degrees, b.Degrees, t),
+		Radius:  radius,
 	}
 }
@@ -0,0 +1,118 @@
+package polar
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6
+}
+
+func TestAddCancels(t *testing.T) {
+	for _, coords := range [][]Coord{
+		{{0, 1}, {180, 1}},
+		{{90, 2}, {270, 2}},
+		{{0, 1}, {120, 1}, {240, 1}},
+		{{45, 3}, {45, 3}, {225, 6}},
+	} {
+		if got := Add(coords...); got != (Coord{}) {
+			t.Errorf("Add(%v) = %v, expected {0, 0}", coords, got)
+		}
+	}
+	if got := Sub(Coord{30, 1}, Coord{30, 1}); got != (Coord{}) {
+		t.Errorf("Sub of equal coordinates = %v, expected {0, 0}", got)
+	}
+	if _, err := Mean(Coord{0, 1}, Coord{180, 1}); !errors.Is(err, ErrZeroResultant) {
+		t.Errorf("Mean of opposite coordinates: got error %v, expected %v", err, ErrZeroResultant)
+	}
+}
+
+func TestArc(t *testing.T) {
+	for _, tc := range []struct {
+		a, b, expected float64
+	}{
+		{0, 90, 90},
+		{90, 0, -90},
+		{350, 10, 20},
+		{10, 350, -20},
+		{0, 180, -180},
+		{180, 0, -180},
+		{-90, 630, 0},
+	} {
+		if got := Arc(tc.a, tc.b); !near(got, tc.expected) {
+			t.Errorf("Arc(%v, %v) = %v, expected %v", tc.a, tc.b, got, tc.expected)
+		}
+	}
+}
+
+func TestLerp(t *testing.T) {
+	for _, tc := range []struct {
+		a, b     Coord
+		t        float64
+		expected Coord
+	}{
+		{Coord{350, 1}, Coord{10, 3}, 0.5, Coord{0, 2}},
+		{Coord{350, 1}, Coord{10, 3}, 0, Coord{350, 1}},
+		{Coord{350, 1}, Coord{10, 3}, 1, Coord{10, 3}},
+		{Coord{0, 0}, Coord{90, 2}, 0.25, Coord{90, 0.5}},
+		{Coord{270, 2}, Coord{90, 0}, 0.5, Coord{270, 1}},
+		{Coord{45, 0}, Coord{90, 0}, 0.5, Coord{0, 0}},
+	} {
+		got := Lerp(tc.a, tc.b, tc.t)
+		if !near(got.Degrees, tc.expected.Degrees) || !near(got.Radius, tc.expected.Radius) {
+			t.Errorf("Lerp(%v, %v, %v) = %v, expected %v", tc.a, tc.b, tc.t, got, tc.expected)
+		}
+	}
+}
+
+func TestVariance(t *testing.T) {
+	for _, tc := range []struct {
+		coords   []Coord
+		expected float64
+	}{
+		{[]Coord{{30, 1}, {30, 5}}, 0},
+		{[]Coord{{0, 1}, {180, 1}}, 1},
+		{[]Coord{{0, 1}, {90, 1}, {180, 1}, {270, 1}}, 1},
+		{[]Coord{{0, 1}, {90, 1}}, 1 - math.Sqrt2/2},
+		{[]Coord{{0, 3}, {180, 1}}, 0.5},
+	} {
+		got, err := Variance(tc.coords...)
+		if err != nil {
+			t.Errorf("Variance(%v): %v", tc.coords, err)
+			continue
+		}
+		if !near(got, tc.expected) {
+			t.Errorf("Variance(%v) = %v, expected %v", tc.coords, got, tc.expected)
+		}
+	}
+	if _, err := Variance(Coord{10, 0}); !errors.Is(err, ErrZeroResultant) {
+		t.Errorf("Variance of zero radii: got error %v, expected %v", err, ErrZeroResultant)
+	}
+}
+
+func TestWeightedMean(t *testing.T) {
+	got, err := WeightedMean([]Coord{{0, 1}, {90, 1}}, []float64{1, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := fromXY(0.25, 0.75)
+	if !near(got.Degrees, expected.Degrees) || !near(got.Radius, expected.Radius) {
+		t.Errorf("WeightedMean = %v, expected %v", got, expected)
+	}
+	for _, tc := range []struct {
+		coords  []Coord
+		weights []float64
+	}{
+		{[]Coord{{0, 1}, {180, 1}}, []float64{2, 2}},
+		{[]Coord{{0, 1}, {90, 1}}, []float64{1, -1}},
+	} {
+		if _, err := WeightedMean(tc.coords, tc.weights); !errors.Is(err, ErrZeroResultant) {
+			t.Errorf("WeightedMean(%v, %v): got error %v, expected %v", tc.coords, tc.weights, err, ErrZeroResultant)
+		}
+	}
+	if _, err := WeightedMean([]Coord{{0, 1}}, nil); err == nil {
+		t.Error("WeightedMean with missing weights: expected an error")
+	}
+}
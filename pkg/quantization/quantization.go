@@ -0,0 +1,313 @@
+// Package quantization measures how far colors move when a palette
+// quantizes them, i.e. the color difference between c and
+// Select(Nearest(c)), over a plane of color space.
+package quantization
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/chrisfenner/bytecolor/pkg/deltae"
+	"github.com/chrisfenner/bytecolor/pkg/ramp"
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+type rgb = [3]byte
+
+type Palette interface {
+	// Select returns 8bpc R,G,B values for a given byte value
+	Select(b byte) rgb
+	// Nearest returns the byte value corresponding to the approximate color
+	Nearest(c color.Color) byte
+}
+
+// Plane selects the slice of color space that is swept.
+type Plane int
+
+const (
+	// HSL sweeps hue across and lightness down, at a fixed saturation.
+	HSL Plane = iota
+	// OKLab sweeps the a (green-red) axis across and the b (blue-yellow)
+	// axis down, at a fixed lightness. Colors outside sRGB are skipped.
+	OKLab
+)
+
+var planeNames = []string{"hsl", "oklab"}
+
+func (p Plane) String() string {
+	if p < 0 || int(p) >= len(planeNames) {
+		return fmt.Sprintf("Plane(%d)", int(p))
+	}
+	return planeNames[p]
+}
+
+// ParsePlane returns the plane with the given name, as printed by String.
+func ParsePlane(name string) (Plane, error) {
+	for i, n := range planeNames {
+		if strings.EqualFold(n, name) {
+			return Plane(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unrecognized plane '%s', only %s are supported", name, strings.Join(planeNames, ", "))
+}
+
+// Options controls the sweep. The zero value (or nil) sweeps the HSL plane
+// at full saturation.
+type Options struct {
+	Plane Plane
+	// Width and Height are the number of samples across and down. 0 means
+	// 360 and 180.
+	Width, Height int
+	// Fixed is the saturation of the HSL plane (0 means 1) or the lightness
+	// of the OKLab plane (0 means 0.7), from 0 to 1.
+	Fixed float64
+}
+
+// oklabMax is the largest a or b value swept in the OKLab plane.
+const oklabMax = 0.3
+
+// Map holds the result of a sweep, row by row.
+type Map struct {
+	Plane         Plane
+	Width, Height int
+	// Input is the color swept at each sample.
+	Input []rgb
+	// Nearest is the byte picked for each sample.
+	Nearest []byte
+	// DeltaE is the CIEDE2000 difference between each input and the
+	// color of its nearest byte, or NaN where the input is not in sRGB.
+	DeltaE []float64
+}
+
+// Sweep quantizes a plane of colors with p.
+func Sweep(p Palette, opts *Options) (*Map, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	w, h := opts.Width, opts.Height
+	if w == 0 {
+		w = 360
+	}
+	if h == 0 {
+		h = 180
+	}
+	if w < 0 || h < 0 {
+		return nil, fmt.Errorf("width and height must not be negative")
+	}
+	if opts.Fixed < 0 || opts.Fixed > 1 {
+		return nil, fmt.Errorf("fixed must be between 0 and 1")
+	}
+	m := &Map{
+		Plane:   opts.Plane,
+		Width:   w,
+		Height:  h,
+		Input:   make([]rgb, w*h),
+		Nearest: make([]byte, w*h),
+		DeltaE:  make([]float64, w*h),
+	}
+	// Quantize each distinct input color once.
+	cache := make(map[rgb]byte)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			i := y*w + x
+			c, ok := sample(opts, x, y, w, h)
+			if !ok {
+				m.DeltaE[i] = math.NaN()
+				continue
+			}
+			r, g, b := c.Clamped().RGB255()
+			in := rgb{r, g, b}
+			n, ok := cache[in]
+			if !ok {
+				n = p.Nearest(color.RGBA{r, g, b, 255})
+				cache[in] = n
+			}
+			m.Input[i] = in
+			m.Nearest[i] = n
+			m.DeltaE[i] = deltae.RGB(in, p.Select(n))
+		}
+	}
+	return m, nil
+}
+
+// sample returns the color at (x, y) of a w x h sweep, and false if it is
+// outside sRGB.
+func sample(opts *Options, x, y, w, h int) (colorful.Color, bool) {
+	fx := (float64(x) + 0.5) / float64(w)
+	fy := (float64(y) + 0.5) / float64(h)
+	switch opts.Plane {
+	case OKLab:
+		l := opts.Fixed
+		if l == 0 {
+			l = 0.7
+		}
+		return fromOKLab(l, oklabMax*(2*fx-1), oklabMax*(2*fy-1))
+	default:
+		s := opts.Fixed
+		if s == 0 {
+			s = 1
+		}
+		return colorful.Hsl(360*fx, s, fy), true
+	}
+}
+
+// fromOKLab converts an OKLab color to sRGB, and reports whether it is in
+// gamut. See https://bottosson.github.io/posts/oklab/.
+func fromOKLab(l, a, b float64) (colorful.Color, bool) {
+	l1 := l + 0.3963377774*a + 0.2158037573*b
+	m1 := l - 0.1055613458*a - 0.0638541728*b
+	s1 := l - 0.0894841775*a - 1.2914855480*b
+	l3, m3, s3 := l1*l1*l1, m1*m1*m1, s1*s1*s1
+	r := 4.0767416621*l3 - 3.3077115913*m3 + 0.2309699292*s3
+	g := -1.2684380046*l3 + 2.6097574011*m3 - 0.3413193965*s3
+	bl := -0.0041960863*l3 - 0.7034186147*m3 + 1.7076147010*s3
+	const eps = 1e-4
+	for _, v := range []float64{r, g, bl} {
+		if v < -eps || v > 1+eps {
+			return colorful.Color{}, false
+		}
+	}
+	return colorful.LinearRgb(r, g, bl), true
+}
+
+// Valid reports whether sample i is in sRGB.
+func (m *Map) Valid(i int) bool {
+	return !math.IsNaN(m.DeltaE[i])
+}
+
+// Stats returns the mean and maximum difference over the valid samples.
+func (m *Map) Stats() (mean, max float64) {
+	n := 0
+	for i, d := range m.DeltaE {
+		if !m.Valid(i) {
+			continue
+		}
+		mean += d
+		if d > max {
+			max = d
+		}
+		n++
+	}
+	if n > 0 {
+		mean /= float64(n)
+	}
+	return mean, max
+}
+
+// Image draws the map with one pixel per sample, mapping differences from 0
+// to full on r (nil means ramp.Heat). Samples outside sRGB are transparent.
+func (m *Map) Image(r ramp.Ramp, full float64) *image.RGBA {
+	if r == nil {
+		r = ramp.Heat
+	}
+	result := image.NewRGBA(image.Rect(0, 0, m.Width, m.Height))
+	for i, d := range m.DeltaE {
+		if !m.Valid(i) {
+			continue
+		}
+		result.SetRGBA(i%m.Width, i/m.Width, r(d/full))
+	}
+	return result
+}
+
+// Region is a named part of color space, such as "dark blue", with the
+// differences of the samples that fall in it.
+type Region struct {
+	Name       string
+	Samples    int
+	MeanDeltaE float64
+	MaxDeltaE  float64
+}
+
+func (r Region) String() string {
+	samples := "samples"
+	if r.Samples == 1 {
+		samples = "sample"
+	}
+	return fmt.Sprintf("%s: mean dE %.1f, max %.1f (%d %s)", r.Name, r.MeanDeltaE, r.MaxDeltaE, r.Samples, samples)
+}
+
+// hueNames name the twelve 30-degree sectors of the HSL hue circle,
+// centered on 0, 30, 60 degrees and so on.
+var hueNames = []string{
+	"red", "orange", "yellow", "chartreuse", "green", "spring green",
+	"cyan", "azure", "blue", "violet", "magenta", "rose",
+}
+
+// regionName names the region of an input color by its HSL hue (or gray,
+// if it has little saturation) and its lightness.
+func regionName(c rgb) string {
+	h, s, l := deltae.FromRGB(c).Hsl()
+	band := "mid"
+	switch {
+	case l < 1.0/3:
+		band = "dark"
+	case l > 2.0/3:
+		band = "light"
+	}
+	if s < 0.15 {
+		return band + " gray"
+	}
+	sector := int(math.Floor((h+15)/30)) % len(hueNames)
+	return band + " " + hueNames[sector]
+}
+
+// Regions returns the differences grouped by region, worst (highest mean)
+// first.
+func (m *Map) Regions() []Region {
+	byName := make(map[string]*Region)
+	var result []*Region
+	for i, d := range m.DeltaE {
+		if !m.Valid(i) {
+			continue
+		}
+		name := regionName(m.Input[i])
+		r, ok := byName[name]
+		if !ok {
+			r = &Region{Name: name}
+			byName[name] = r
+			result = append(result, r)
+		}
+		r.Samples++
+		r.MeanDeltaE += d
+		if d > r.MaxDeltaE {
+			r.MaxDeltaE = d
+		}
+	}
+	regions := make([]Region, len(result))
+	for i, r := range result {
+		r.MeanDeltaE /= float64(r.Samples)
+		regions[i] = *r
+	}
+	sort.SliceStable(regions, func(i, j int) bool {
+		return regions[i].MeanDeltaE > regions[j].MeanDeltaE
+	})
+	return regions
+}
+
+// MinRegionShare is the smallest share of the valid samples that a region
+// must hold to be listed by Poor. The mean of a sliver of a region, such as
+// the few samples of it that fall on a small panel, says little about it.
+const MinRegionShare = 0.01
+
+// Poor returns the regions whose mean difference is at least threshold,
+// worst first, leaving out those with less than MinRegionShare of the
+// samples.
+func (m *Map) Poor(threshold float64) []Region {
+	regions := m.Regions()
+	total := 0
+	for _, r := range regions {
+		total += r.Samples
+	}
+	var result []Region
+	for _, r := range regions {
+		if r.MeanDeltaE >= threshold && float64(r.Samples) >= MinRegionShare*float64(total) {
+			result = append(result, r)
+		}
+	}
+	return result
+}
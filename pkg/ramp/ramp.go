@@ -0,0 +1,55 @@
+// Package ramp maps scalar values to colors, for drawing measurements
+// (entropy, counts, errors) next to or on top of palette colors.
+package ramp
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// Ramp maps a value in [0, 1] to a color.
+type Ramp = func(t float64) color.RGBA
+
+// stops of the heat ramp: black, through blue, magenta, orange and yellow, to white.
+var heatStops = []colorful.Color{
+	{R: 0.0, G: 0.0, B: 0.0},
+	{R: 0.1, G: 0.1, B: 0.6},
+	{R: 0.7, G: 0.1, B: 0.6},
+	{R: 1.0, G: 0.5, B: 0.0},
+	{R: 1.0, G: 0.9, B: 0.2},
+	{R: 1.0, G: 1.0, B: 1.0},
+}
+
+// Heat is a perceptually ordered ramp from black (0) to white (1).
+func Heat(t float64) color.RGBA {
+	return along(heatStops, t)
+}
+
+// Gray is a ramp from black (0) to white (1).
+func Gray(t float64) color.RGBA {
+	v := byte(math.Round(clamp(t) * 255))
+	return color.RGBA{v, v, v, 255}
+}
+
+func along(stops []colorful.Color, t float64) color.RGBA {
+	t = clamp(t) * float64(len(stops)-1)
+	i := int(t)
+	if i >= len(stops)-1 {
+		i = len(stops) - 2
+	}
+	c := stops[i].BlendLab(stops[i+1], t-float64(i)).Clamped()
+	r, g, b := c.RGB255()
+	return color.RGBA{r, g, b, 255}
+}
+
+func clamp(t float64) float64 {
+	if math.IsNaN(t) || t < 0 {
+		return 0
+	}
+	if t > 1 {
+		return 1
+	}
+	return t
+}
@@ -0,0 +1,175 @@
+package registry
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"image/color"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/chrisfenner/bytecolor/pkg/hcl"
+	"github.com/chrisfenner/bytecolor/pkg/hsl"
+	"github.com/chrisfenner/bytecolor/pkg/hsv"
+	"github.com/chrisfenner/bytecolor/pkg/luv"
+	"github.com/chrisfenner/bytecolor/pkg/windows"
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+type rgb = [3]byte
+
+type Palette interface {
+	// Select returns 8bpc R,G,B values for a given byte value
+	Select(b byte) rgb
+	// Nearest returns the byte value corresponding to the approximate color
+	Nearest(c color.Color) byte
+}
+
+// Constructor builds a new instance of a named palette.
+type Constructor = func() (Palette, error)
+
+var (
+	mu           sync.RWMutex
+	constructors = map[string]Constructor{
+		"hsl": func() (Palette, error) { return hsl.New() },
+		"hsv": func() (Palette, error) { return hsv.New() },
+		"hcl": func() (Palette, error) { return hcl.New() },
+		"luv": func() (Palette, error) { return luv.New() },
+		"win": func() (Palette, error) { return windows.New() },
+	}
+)
+
+// Register makes a palette available by name to every tool that uses the registry.
+// Names are case-insensitive. Registering the same name twice is an error.
+func Register(name string, c Constructor) error {
+	mu.Lock()
+	defer mu.Unlock()
+	name = strings.ToLower(name)
+	if _, ok := constructors[name]; ok {
+		return fmt.Errorf("palette '%s' is already registered", name)
+	}
+	constructors[name] = c
+	return nil
+}
+
+// Names returns the names of all the registered palettes, sorted.
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	result := make([]string, 0, len(constructors))
+	for name := range constructors {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// New returns a new instance of the palette registered under the given name.
+func New(name string) (Palette, error) {
+	mu.RLock()
+	c, ok := constructors[strings.ToLower(name)]
+	mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unsupported palette '%s'", name)
+	}
+	return c()
+}
+
+// Load returns the palette described by spec, which is either the name of a
+// registered palette or the path to a palette file as written by Dump.
+func Load(spec string) (Palette, error) {
+	mu.RLock()
+	_, ok := constructors[strings.ToLower(spec)]
+	mu.RUnlock()
+	if ok {
+		return New(spec)
+	}
+	if _, err := os.Stat(spec); err != nil {
+		return nil, fmt.Errorf("'%s' is neither a registered palette (%s) nor a palette file", spec, strings.Join(Names(), ", "))
+	}
+	return ReadFile(spec)
+}
+
+// Dump writes the 256 colors of a palette to a file, one hex RGB value per line.
+func Dump(path string, p Palette) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	for i := 0; i < 256; i++ {
+		c := p.Select(byte(i))
+		fmt.Fprintf(w, "%s\n", hex.EncodeToString(c[:]))
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ReadFile reads a palette file as written by Dump.
+// Blank lines and lines starting with '#' are ignored.
+func ReadFile(path string) (Palette, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var p fixed
+	n := 0
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		if n == 256 {
+			return nil, fmt.Errorf("%s:%d: more than 256 colors", path, line)
+		}
+		b, err := hex.DecodeString(strings.TrimPrefix(text, "#"))
+		if err != nil || len(b) != 3 {
+			return nil, fmt.Errorf("%s:%d: '%s' is not a hex RGB value", path, line, text)
+		}
+		copy(p[n][:], b)
+		n++
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if n != 256 {
+		return nil, fmt.Errorf("%s: expected 256 colors, found %d", path, n)
+	}
+	return &p, nil
+}
+
+// fixed is a palette read from a file.
+type fixed [256]rgb
+
+func (p *fixed) Select(b byte) [3]byte {
+	return p[b]
+}
+
+// Distance returns the distance between two colors, as used by Nearest.
+func (p *fixed) Distance(c1, c2 colorful.Color) float64 {
+	return c1.DistanceRgb(c2)
+}
+
+func (p *fixed) Nearest(c color.Color) byte {
+	best := byte(0)
+	bestDist := math.MaxFloat64
+	col, _ := colorful.MakeColor(c)
+	for i := 0; i < 256; i++ {
+		rgb := p.Select(byte(i))
+		dist := p.Distance(col, colorful.Color{R: float64(rgb[0]) / 255.0, G: float64(rgb[1]) / 255.0, B: float64(rgb[2]) / 255.0})
+		if dist < bestDist {
+			bestDist = dist
+			best = byte(i)
+		}
+	}
+	return best
+}
@@ -0,0 +1,49 @@
+package registry
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// builtins are the palettes whose colors are pinned by golden files.
+var builtins = []string{"hcl", "hsl", "hsv", "luv", "win"}
+
+func goldenPath(name string) string {
+	return filepath.Join("testdata", name+".palette")
+}
+
+func TestGoldenPalettes(t *testing.T) {
+	for _, name := range builtins {
+		t.Run(name, func(t *testing.T) {
+			p, err := New(name)
+			if err != nil {
+				t.Fatalf("New(%q): %v", name, err)
+			}
+			if *update {
+				if err := Dump(goldenPath(name), p); err != nil {
+					t.Fatalf("Dump: %v", err)
+				}
+				return
+			}
+			golden, err := ReadFile(goldenPath(name))
+			if err != nil {
+				t.Fatalf("ReadFile: %v (run with -update to create it)", err)
+			}
+			var diffs []string
+			for i := 0; i < 256; i++ {
+				want, got := golden.Select(byte(i)), p.Select(byte(i))
+				if want != got {
+					diffs = append(diffs, fmt.Sprintf("0x%02x: golden #%02x%02x%02x, got #%02x%02x%02x", i, want[0], want[1], want[2], got[0], got[1], got[2]))
+				}
+			}
+			if diffs != nil {
+				t.Errorf("%d of 256 colors changed (run with -update if intended):\n%s", len(diffs), strings.Join(diffs, "\n"))
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package render
+
+import (
+	"fmt"
+	"math/bits"
+	"strings"
+
+	"github.com/chrisfenner/bytecolor/pkg/entropy"
+	"github.com/chrisfenner/bytecolor/pkg/ramp"
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// Aggregate selects how a pixel that covers more than one byte is summarized.
+type Aggregate int
+
+const (
+	// MostFrequent shows the most frequent byte (the smallest one, in case of a tie).
+	MostFrequent Aggregate = iota
+	// First shows the first byte.
+	First
+	// MaxPopcount shows the byte with the most bits set (the most frequent
+	// one, in case of a tie). Sparse flags and bitmaps stand out.
+	MaxPopcount
+	// MeanColor shows the mean of the colors of the bytes, taken in CIE Lab.
+	MeanColor
+	// EntropyRamp shows the entropy of the bytes on the heat ramp.
+	EntropyRamp
+)
+
+var aggregateNames = []string{"frequent", "first", "popcount", "mean", "entropy"}
+
+// AggregateNames returns the names understood by ParseAggregate.
+func AggregateNames() []string {
+	return append([]string(nil), aggregateNames...)
+}
+
+// ParseAggregate returns the aggregation mode with the given name.
+func ParseAggregate(name string) (Aggregate, error) {
+	for i, n := range aggregateNames {
+		if strings.EqualFold(n, name) {
+			return Aggregate(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unsupported aggregation mode '%s', only %s are supported", name, strings.Join(aggregateNames, ", "))
+}
+
+func (a Aggregate) String() string {
+	if a < 0 || int(a) >= len(aggregateNames) {
+		return fmt.Sprintf("Aggregate(%d)", int(a))
+	}
+	return aggregateNames[a]
+}
+
+// Indexed reports whether the mode always picks one of the bytes, so that
+// the result can be drawn with the palette's own colors.
+func (a Aggregate) Indexed() bool {
+	return a == MostFrequent || a == First || a == MaxPopcount
+}
+
+// Summary tallies the bytes covered by one pixel. The zero value is empty.
+type Summary struct {
+	counts [256]uint64
+	n      uint64
+	first  byte
+	// seen lists the distinct bytes tallied, so that a summary of a few
+	// bytes can be reset and read without visiting all 256 counts.
+	seen  [256]byte
+	nseen int
+}
+
+// Reset empties the summary.
+func (s *Summary) Reset() {
+	for _, b := range s.seen[:s.nseen] {
+		s.counts[b] = 0
+	}
+	s.nseen = 0
+	s.n = 0
+}
+
+// Add tallies data.
+func (s *Summary) Add(data []byte) {
+	if s.n == 0 && len(data) > 0 {
+		s.first = data[0]
+	}
+	for _, b := range data {
+		if s.counts[b] == 0 {
+			s.seen[s.nseen] = b
+			s.nseen++
+		}
+		s.counts[b]++
+	}
+	s.n += uint64(len(data))
+}
+
+// Len returns the number of bytes tallied.
+func (s *Summary) Len() uint64 {
+	return s.n
+}
+
+// MostFrequent returns the most frequent byte (the smallest one, in case of a tie).
+func (s *Summary) MostFrequent() byte {
+	best := -1
+	for _, b := range s.seen[:s.nseen] {
+		i := int(b)
+		if best < 0 || s.counts[i] > s.counts[best] || (s.counts[i] == s.counts[best] && i < best) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return 0
+	}
+	return byte(best)
+}
+
+// Index summarizes the tallied bytes as one of them. Modes that are not
+// Indexed fall back to MostFrequent.
+func (s *Summary) Index(a Aggregate) byte {
+	switch a {
+	case First:
+		return s.first
+	case MaxPopcount:
+		best := -1
+		for _, b := range s.seen[:s.nseen] {
+			i := int(b)
+			if best < 0 {
+				best = i
+				continue
+			}
+			pi, pb := bits.OnesCount8(byte(i)), bits.OnesCount8(byte(best))
+			if pi > pb ||
+				(pi == pb && s.counts[i] > s.counts[best]) ||
+				(pi == pb && s.counts[i] == s.counts[best] && i < best) {
+				best = i
+			}
+		}
+		if best < 0 {
+			return 0
+		}
+		return byte(best)
+	default:
+		return s.MostFrequent()
+	}
+}
+
+// Colorizer summarizes tallied bytes as colors, for any aggregation mode.
+type Colorizer struct {
+	agg    Aggregate
+	colors [256]rgb
+	labs   [256][3]float64
+}
+
+// NewColorizer returns a Colorizer for the given palette and mode.
+func NewColorizer(p Palette, agg Aggregate) *Colorizer {
+	c := &Colorizer{agg: agg}
+	for i := range c.colors {
+		c.colors[i] = p.Select(byte(i))
+		l, a, b := colorful.Color{
+			R: float64(c.colors[i][0]) / 255.0,
+			G: float64(c.colors[i][1]) / 255.0,
+			B: float64(c.colors[i][2]) / 255.0,
+		}.Lab()
+		c.labs[i] = [3]float64{l, a, b}
+	}
+	return c
+}
+
+// Color returns the color summarizing s. Every mode uses the same formula
+// however many bytes s holds, so that one byte and a block of bytes that
+// are all the same are shown alike.
+func (c *Colorizer) Color(s *Summary) rgb {
+	switch c.agg {
+	case MeanColor:
+		if s.n == 0 {
+			return c.colors[0]
+		}
+		// The mean of a single color is exactly that color, which the trip
+		// through Lab would only approximate.
+		if s.nseen == 1 {
+			return c.colors[s.seen[0]]
+		}
+		var sum [3]float64
+		for _, b := range s.seen[:s.nseen] {
+			for j := range sum {
+				sum[j] += float64(s.counts[b]) * c.labs[b][j]
+			}
+		}
+		for j := range sum {
+			sum[j] /= float64(s.n)
+		}
+		r, g, b := colorful.Lab(sum[0], sum[1], sum[2]).Clamped().RGB255()
+		return rgb{r, g, b}
+	case EntropyRamp:
+		col := ramp.Heat(entropy.FromCounts(&s.counts) / entropy.Max)
+		return rgb{col.R, col.G, col.B}
+	default:
+		return c.colors[s.Index(c.agg)]
+	}
+}
@@ -0,0 +1,141 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+
+	"github.com/chrisfenner/bytecolor/pkg/entropy"
+	"github.com/chrisfenner/bytecolor/pkg/ramp"
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// EntropyMode selects how entropy is drawn together with the bytes.
+type EntropyMode int
+
+const (
+	// EntropyBand draws the entropy of each row of pixels as a band along
+	// the right edge of the image.
+	EntropyBand EntropyMode = iota
+	// EntropyOverlay fades the colors of low-entropy bytes towards gray.
+	EntropyOverlay
+)
+
+// EntropyOptions controls how entropy is measured and drawn.
+type EntropyOptions struct {
+	// Window is the number of bytes over which each entropy value is measured.
+	Window int
+	// Step is the distance in bytes between the start of consecutive windows.
+	Step int
+	// Mode selects a band or an overlay.
+	Mode EntropyMode
+	// BandWidth is the width in pixels of the band. 0 means 16.
+	BandWidth int
+	// Ramp colors the band. nil means ramp.Heat.
+	Ramp ramp.Ramp
+}
+
+// fadeLevels is the number of distinct amounts of fading used by the overlay.
+const fadeLevels = 32
+
+// WithEntropy renders data like Bytes, then draws its sliding-window entropy
+// on it. It also returns the entropy series (see entropy.Series) of the
+// rendered part of the data.
+func WithEntropy(data []byte, p Palette, width int, opts *Options, eopts *EntropyOptions) (*image.RGBA, []float64, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	if eopts == nil {
+		return nil, nil, fmt.Errorf("entropy options are required")
+	}
+	data, err := slice(data, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	series, err := entropy.Series(data, eopts.Window, eopts.Step)
+	if err != nil {
+		return nil, nil, err
+	}
+	pix, err := indices(data, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	m, err := paintIndices(pix, p, width, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	l, scale, err := canvas(int64(len(pix)), width, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	bounds := l.Bounds()
+	// pixelEntropy returns the entropy of the window holding the first byte of pixel i.
+	bpp := opts.BytesPerPixel
+	if bpp < 1 {
+		bpp = 1
+	}
+	pixelEntropy := func(i int64) float64 {
+		return series[i*bpp/int64(eopts.Step)]
+	}
+
+	switch eopts.Mode {
+	case EntropyBand:
+		bandWidth := eopts.BandWidth
+		if bandWidth == 0 {
+			bandWidth = 16
+		}
+		r := eopts.Ramp
+		if r == nil {
+			r = ramp.Heat
+		}
+		result := image.NewRGBA(image.Rect(0, 0, m.Rect.Dx()+bandWidth, m.Rect.Dy()))
+		draw.Draw(result, m.Rect, m, image.Point{}, draw.Src)
+		// Average the entropy of the pixels drawn on each row of the layout.
+		sums := make([]float64, bounds.Dy())
+		counts := make([]int, bounds.Dy())
+		for i := range pix {
+			_, y := l.Point(int64(i))
+			sums[y-bounds.Min.Y] += pixelEntropy(int64(i))
+			counts[y-bounds.Min.Y]++
+		}
+		for y := range sums {
+			if counts[y] == 0 {
+				continue
+			}
+			c := r(sums[y] / float64(counts[y]) / entropy.Max)
+			draw.Draw(result, image.Rect(m.Rect.Dx(), y*scale, m.Rect.Dx()+bandWidth, (y+1)*scale), image.NewUniform(c), image.Point{}, draw.Src)
+		}
+		return result, series, nil
+	case EntropyOverlay:
+		faded := fadeTable(p)
+		result := image.NewRGBA(m.Rect)
+		draw.Draw(result, m.Rect, m, image.Point{}, draw.Src)
+		place(l, scale, int64(len(pix)), func(i int64, x, y int) {
+			level := int(pixelEntropy(i) / entropy.Max * (fadeLevels - 1))
+			result.SetRGBA(x, y, faded[pix[i]][level])
+		})
+		return result, series, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported entropy mode %d", eopts.Mode)
+	}
+}
+
+// fadeTable precomputes every palette color at every level of fading.
+// Level 0 (no entropy) is fully faded, level fadeLevels-1 is the original color.
+func fadeTable(p Palette) [256][fadeLevels]color.RGBA {
+	var result [256][fadeLevels]color.RGBA
+	// Don't fade all the way, so that the structure of the data stays visible.
+	const maxFade = 0.85
+	gray := colorful.Color{R: 0.5, G: 0.5, B: 0.5}
+	for v := 0; v < 256; v++ {
+		rgb := p.Select(byte(v))
+		c := colorful.Color{R: float64(rgb[0]) / 255.0, G: float64(rgb[1]) / 255.0, B: float64(rgb[2]) / 255.0}
+		for level := 0; level < fadeLevels; level++ {
+			t := maxFade * (1 - float64(level)/(fadeLevels-1))
+			r, g, b := c.BlendLab(gray, t).Clamped().RGB255()
+			result[v][level] = color.RGBA{r, g, b, 255}
+		}
+	}
+	return result
+}
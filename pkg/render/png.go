@@ -0,0 +1,151 @@
+package render
+
+import (
+	"bufio"
+	"compress/zlib"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+)
+
+// pngWriter writes a non-interlaced 8-bit PNG one row at a time, so that
+// images far bigger than memory can be encoded.
+// See https://www.w3.org/TR/PNG/ for the format.
+type pngWriter struct {
+	w     *bufio.Writer
+	idat  *chunkWriter
+	z     *zlib.Writer
+	width int
+	bpp   int
+	err   error
+}
+
+const (
+	pngColorTruecolor = 2
+	pngColorPaletted  = 3
+	// pngMaxSize is the largest width or height of a PNG image.
+	pngMaxSize = 1<<31 - 1
+)
+
+// newPNGWriter writes the PNG header and returns a writer expecting height
+// rows. If palette is not nil, the image is paletted with one byte per pixel;
+// otherwise it is truecolor with three bytes (R, G, B) per pixel.
+func newPNGWriter(w io.Writer, width, height int, palette []rgb) (*pngWriter, error) {
+	pw := &pngWriter{
+		w:     bufio.NewWriter(w),
+		width: width,
+		bpp:   3,
+	}
+	colorType := byte(pngColorTruecolor)
+	if palette != nil {
+		colorType = pngColorPaletted
+		pw.bpp = 1
+	}
+	if _, err := pw.w.Write([]byte("\x89PNG\r\n\x1a\n")); err != nil {
+		return nil, err
+	}
+	var ihdr [13]byte
+	binary.BigEndian.PutUint32(ihdr[0:4], uint32(width))
+	binary.BigEndian.PutUint32(ihdr[4:8], uint32(height))
+	ihdr[8] = 8 // bit depth
+	ihdr[9] = colorType
+	// compression, filter and interlace methods are all 0.
+	if err := writeChunk(pw.w, "IHDR", ihdr[:]); err != nil {
+		return nil, err
+	}
+	if palette != nil {
+		plte := make([]byte, 0, 3*len(palette))
+		for _, c := range palette {
+			plte = append(plte, c[:]...)
+		}
+		if err := writeChunk(pw.w, "PLTE", plte); err != nil {
+			return nil, err
+		}
+	}
+	pw.idat = &chunkWriter{w: pw.w, name: "IDAT"}
+	pw.z = zlib.NewWriter(pw.idat)
+	return pw, nil
+}
+
+// WriteRow writes one row of pixels, which must be width*bpp bytes long.
+func (pw *pngWriter) WriteRow(row []byte) error {
+	if pw.err != nil {
+		return pw.err
+	}
+	// Every row starts with its filter type; 0 means unfiltered.
+	if _, pw.err = pw.z.Write([]byte{0}); pw.err != nil {
+		return pw.err
+	}
+	_, pw.err = pw.z.Write(row)
+	return pw.err
+}
+
+// Close finishes the image. It does not close the underlying writer.
+func (pw *pngWriter) Close() error {
+	if pw.err != nil {
+		return pw.err
+	}
+	if err := pw.z.Close(); err != nil {
+		return err
+	}
+	if err := pw.idat.Flush(); err != nil {
+		return err
+	}
+	if err := writeChunk(pw.w, "IEND", nil); err != nil {
+		return err
+	}
+	return pw.w.Flush()
+}
+
+func writeChunk(w io.Writer, name string, data []byte) error {
+	var header [8]byte
+	binary.BigEndian.PutUint32(header[0:4], uint32(len(data)))
+	copy(header[4:8], name)
+	crc := crc32.NewIEEE()
+	crc.Write(header[4:8])
+	crc.Write(data)
+	var footer [4]byte
+	binary.BigEndian.PutUint32(footer[:], crc.Sum32())
+	for _, b := range [][]byte{header[:], data, footer[:]} {
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// chunkWriter splits everything written to it into chunks of bounded size.
+type chunkWriter struct {
+	w    io.Writer
+	name string
+	buf  []byte
+}
+
+const maxChunkSize = 1 << 16
+
+func (c *chunkWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		room := maxChunkSize - len(c.buf)
+		if room > len(p) {
+			room = len(p)
+		}
+		c.buf = append(c.buf, p[:room]...)
+		p = p[room:]
+		if len(c.buf) == maxChunkSize {
+			if err := c.Flush(); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return n, nil
+}
+
+func (c *chunkWriter) Flush() error {
+	if len(c.buf) == 0 {
+		return nil
+	}
+	err := writeChunk(c.w, c.name, c.buf)
+	c.buf = c.buf[:0]
+	return err
+}
@@ -0,0 +1,256 @@
+// Package render draws binary data as images, one palette color per byte
+// (or per group of bytes, for overviews of large files).
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"io"
+	"io/ioutil"
+
+	"github.com/chrisfenner/bytecolor/pkg/layout"
+)
+
+type rgb = [3]byte
+
+type Palette interface {
+	// Select returns 8bpc R,G,B values for a given byte value
+	Select(b byte) rgb
+	// Nearest returns the byte value corresponding to the approximate color
+	Nearest(c color.Color) byte
+}
+
+// Options controls how the data is rendered. The zero value (or nil) renders
+// all of the data at one pixel per byte.
+type Options struct {
+	// Scale is the width and height in pixels of each byte. 0 means 1.
+	Scale int
+	// Offset is the index of the first byte to render.
+	Offset int64
+	// Length is the number of bytes to render. 0 means up to the end.
+	Length int64
+	// Layout arranges the bytes on the canvas. nil means row-major with the
+	// given width; otherwise the width is ignored.
+	Layout layout.Constructor
+	// Pad is the index of pixels on the canvas that are past the end of the data.
+	Pad byte
+	// BytesPerPixel is the number of bytes summarized by each pixel. 0 means 1.
+	BytesPerPixel int64
+	// Aggregate selects how each pixel summarizes its bytes, when
+	// BytesPerPixel is more than 1.
+	Aggregate Aggregate
+}
+
+// ColorPalette returns the 256 colors of p, indexed by byte value.
+func ColorPalette(p Palette) color.Palette {
+	colors := color.Palette(make([]color.Color, 256))
+	for i := 0; i < 256; i++ {
+		rgb := p.Select(byte(i))
+		colors[i] = color.RGBA{rgb[0], rgb[1], rgb[2], 255}
+	}
+	return colors
+}
+
+// Bytes renders data as an image that is width pixels wide. The index of each
+// pixel is exactly the value of the byte it represents (or, with more than one
+// byte per pixel, the byte picked by opts.Aggregate, which must be Indexed).
+// Pixels past the end of the data have index opts.Pad.
+func Bytes(data []byte, p Palette, width int, opts *Options) (*image.Paletted, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	data, err := slice(data, opts)
+	if err != nil {
+		return nil, err
+	}
+	return paint(data, p, width, opts)
+}
+
+// Overview renders data like Bytes, but also supports the aggregation modes
+// that produce colors outside the palette, in which case the result is an
+// *image.RGBA instead of an *image.Paletted.
+func Overview(data []byte, p Palette, width int, opts *Options) (image.Image, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	if opts.Aggregate.Indexed() || opts.BytesPerPixel <= 1 {
+		return Bytes(data, p, width, opts)
+	}
+	data, err := slice(data, opts)
+	if err != nil {
+		return nil, err
+	}
+	n := pixelCount(int64(len(data)), opts.BytesPerPixel)
+	l, scale, err := canvas(n, width, opts)
+	if err != nil {
+		return nil, err
+	}
+	bounds := l.Bounds()
+	m := image.NewRGBA(image.Rect(0, 0, bounds.Dx()*scale, bounds.Dy()*scale))
+	pad := p.Select(opts.Pad)
+	draw.Draw(m, m.Rect, image.NewUniform(color.RGBA{pad[0], pad[1], pad[2], 255}), image.Point{}, draw.Src)
+	c := NewColorizer(p, opts.Aggregate)
+	var s Summary
+	bpp := int(opts.BytesPerPixel)
+	// place visits all the scaled pixels of a block in a row, so the block
+	// is only summarized on the first of them.
+	last := int64(-1)
+	var col rgb
+	place(l, scale, n, func(i int64, x, y int) {
+		if i != last {
+			start := int(i) * bpp
+			end := start + bpp
+			if end > len(data) {
+				end = len(data)
+			}
+			s.Reset()
+			s.Add(data[start:end])
+			col = c.Color(&s)
+			last = i
+		}
+		m.SetRGBA(x, y, color.RGBA{col[0], col[1], col[2], 255})
+	})
+	return m, nil
+}
+
+// slice returns the part of data selected by opts.Offset and opts.Length.
+func slice(data []byte, opts *Options) ([]byte, error) {
+	if opts.Offset < 0 || opts.Offset > int64(len(data)) {
+		return nil, fmt.Errorf("offset %d is outside the data (%d bytes)", opts.Offset, len(data))
+	}
+	data = data[opts.Offset:]
+	if opts.Length < 0 {
+		return nil, fmt.Errorf("length must not be negative")
+	}
+	if opts.Length != 0 && opts.Length < int64(len(data)) {
+		data = data[:opts.Length]
+	}
+	return data, nil
+}
+
+// Reader renders the data read from r as an image that is width bytes wide.
+// See Bytes.
+func Reader(r io.Reader, p Palette, width int, opts *Options) (*image.Paletted, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	if opts.Offset < 0 {
+		return nil, fmt.Errorf("offset must not be negative")
+	}
+	if opts.Length < 0 {
+		return nil, fmt.Errorf("length must not be negative")
+	}
+	if opts.Offset > 0 {
+		if _, err := io.CopyN(ioutil.Discard, r, opts.Offset); err != nil {
+			return nil, fmt.Errorf("skipping to offset %d: %w", opts.Offset, err)
+		}
+	}
+	if opts.Length > 0 {
+		r = io.LimitReader(r, opts.Length)
+	}
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return paint(data, p, width, opts)
+}
+
+// pixelCount returns the number of pixels needed for n bytes.
+func pixelCount(n, bytesPerPixel int64) int64 {
+	if bytesPerPixel <= 1 {
+		return n
+	}
+	return (n + bytesPerPixel - 1) / bytesPerPixel
+}
+
+// indices returns the palette index of each pixel.
+func indices(data []byte, opts *Options) ([]byte, error) {
+	if opts.BytesPerPixel < 0 {
+		return nil, fmt.Errorf("bytes per pixel must not be negative")
+	}
+	if opts.BytesPerPixel <= 1 {
+		return data, nil
+	}
+	if !opts.Aggregate.Indexed() {
+		return nil, fmt.Errorf("the '%s' aggregation mode does not pick a palette color, use Overview instead", opts.Aggregate)
+	}
+	bpp := int(opts.BytesPerPixel)
+	result := make([]byte, pixelCount(int64(len(data)), opts.BytesPerPixel))
+	var s Summary
+	for i := range result {
+		end := (i + 1) * bpp
+		if end > len(data) {
+			end = len(data)
+		}
+		s.Reset()
+		s.Add(data[i*bpp : end])
+		result[i] = s.Index(opts.Aggregate)
+	}
+	return result, nil
+}
+
+// canvas returns the layout and the pixel scale for n bytes.
+func canvas(n int64, width int, opts *Options) (layout.Layout, int, error) {
+	var l layout.Layout
+	if opts.Layout != nil {
+		l = opts.Layout(n)
+	} else {
+		if width <= 0 {
+			return nil, 0, fmt.Errorf("width must be positive")
+		}
+		l = layout.RowMajor{Width: width, Length: n}
+	}
+	scale := opts.Scale
+	if scale < 0 {
+		return nil, 0, fmt.Errorf("scale must not be negative")
+	}
+	if scale == 0 {
+		scale = 1
+	}
+	return l, scale, nil
+}
+
+// place calls fn for every pixel of the scale x scale block of each of the
+// first n positions of the layout, one whole block after another.
+func place(l layout.Layout, scale int, n int64, fn func(i int64, x, y int)) {
+	bounds := l.Bounds()
+	for i := int64(0); i < n; i++ {
+		x, y := l.Point(i)
+		x = (x - bounds.Min.X) * scale
+		y = (y - bounds.Min.Y) * scale
+		for dy := 0; dy < scale; dy++ {
+			for dx := 0; dx < scale; dx++ {
+				fn(i, x+dx, y+dy)
+			}
+		}
+	}
+}
+
+func paint(data []byte, p Palette, width int, opts *Options) (*image.Paletted, error) {
+	pix, err := indices(data, opts)
+	if err != nil {
+		return nil, err
+	}
+	return paintIndices(pix, p, width, opts)
+}
+
+// paintIndices draws one pixel (or block of pixels, when scaled) per index.
+func paintIndices(pix []byte, p Palette, width int, opts *Options) (*image.Paletted, error) {
+	l, scale, err := canvas(int64(len(pix)), width, opts)
+	if err != nil {
+		return nil, err
+	}
+	bounds := l.Bounds()
+	m := image.NewPaletted(image.Rect(0, 0, bounds.Dx()*scale, bounds.Dy()*scale), ColorPalette(p))
+	if opts.Pad != 0 {
+		for i := range m.Pix {
+			m.Pix[i] = opts.Pad
+		}
+	}
+	place(l, scale, int64(len(pix)), func(i int64, x, y int) {
+		m.Pix[y*m.Stride+x] = pix[i]
+	})
+	return m, nil
+}
@@ -0,0 +1,125 @@
+package render
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// StreamOptions controls Stream.
+type StreamOptions struct {
+	// Size is the number of bytes to read. It must be known up front, since
+	// the height of the image is written before any of the pixels.
+	Size int64
+	// BytesPerPixel is the number of bytes summarized by each pixel.
+	// 0 means 1, unless Height is set.
+	BytesPerPixel int64
+	// Height, if nonzero, overrides BytesPerPixel with the smallest value
+	// that makes the image no more than Height rows tall.
+	Height int
+	// Aggregate selects how each pixel summarizes its bytes. Modes that are
+	// not Indexed produce a truecolor PNG instead of a paletted one.
+	Aggregate Aggregate
+}
+
+// Stream reads opts.Size bytes from r and writes them to w as a PNG that is
+// width pixels wide, one row at a time, using memory that depends only on the
+// width. When each pixel covers more than one byte, it is summarized as
+// selected by opts.Aggregate. Pixels past the end of the data show byte 0.
+func Stream(w io.Writer, r io.Reader, p Palette, width int, opts *StreamOptions) error {
+	if opts == nil || opts.Size <= 0 {
+		return fmt.Errorf("the size of the data is required")
+	}
+	if width <= 0 {
+		return fmt.Errorf("width must be positive")
+	}
+	bpp := opts.BytesPerPixel
+	if opts.Height > 0 {
+		bpp = BytesPerPixel(opts.Size, width, opts.Height)
+	}
+	if bpp < 0 {
+		return fmt.Errorf("bytes per pixel must not be negative")
+	}
+	if bpp == 0 {
+		bpp = 1
+	}
+	pixels := (opts.Size + bpp - 1) / bpp
+	height := (pixels + int64(width) - 1) / int64(width)
+	if height > pngMaxSize {
+		return fmt.Errorf("the image would be %d rows tall, but PNG allows at most %d; use more bytes per pixel or a greater width", height, pngMaxSize)
+	}
+	if int64(width) > pngMaxSize {
+		return fmt.Errorf("width %d is more than PNG allows (%d)", width, pngMaxSize)
+	}
+	// Small pixels mean small reads, which must not each go to r.
+	r = bufio.NewReaderSize(r, 64*1024)
+
+	c := NewColorizer(p, opts.Aggregate)
+	indexed := bpp == 1 || opts.Aggregate.Indexed()
+	var palette []rgb
+	if indexed {
+		palette = c.colors[:]
+	}
+	pw, err := newPNGWriter(w, width, int(height), palette)
+	if err != nil {
+		return err
+	}
+
+	row := make([]byte, width*pw.bpp)
+	buf := make([]byte, 32*1024)
+	var s Summary
+	remaining := opts.Size
+	for y := int64(0); y < height; y++ {
+		for x := 0; x < width; x++ {
+			if remaining == 0 {
+				if indexed {
+					row[x] = 0
+				} else {
+					copy(row[3*x:], c.colors[0][:])
+				}
+				continue
+			}
+			n := bpp
+			if n > remaining {
+				n = remaining
+			}
+			s.Reset()
+			for left := n; left > 0; {
+				chunk := buf
+				if int64(len(chunk)) > left {
+					chunk = chunk[:left]
+				}
+				if _, err := io.ReadFull(r, chunk); err != nil {
+					return fmt.Errorf("reading byte %d of %d: %w", opts.Size-remaining+(n-left), opts.Size, err)
+				}
+				s.Add(chunk)
+				left -= int64(len(chunk))
+			}
+			remaining -= n
+			if indexed {
+				row[x] = s.Index(opts.Aggregate)
+			} else {
+				col := c.Color(&s)
+				copy(row[3*x:], col[:])
+			}
+		}
+		if err := pw.WriteRow(row); err != nil {
+			return err
+		}
+	}
+	return pw.Close()
+}
+
+// BytesPerPixel returns the smallest number of bytes per pixel with which
+// size bytes fit in an image of the given width and height.
+func BytesPerPixel(size int64, width, height int) int64 {
+	pixels := int64(width) * int64(height)
+	if pixels <= 0 {
+		return 1
+	}
+	result := (size + pixels - 1) / pixels
+	if result == 0 {
+		return 1
+	}
+	return result
+}
@@ -0,0 +1,252 @@
+// Package termcolor prints colored text on terminals with whatever color
+// support they have, from 24-bit truecolor down to none at all.
+package termcolor
+
+import (
+	"fmt"
+	"image/color"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/lucasb-eyer/go-colorful"
+	"golang.org/x/term"
+)
+
+type rgb = [3]byte
+
+type Palette interface {
+	// Select returns 8bpc R,G,B values for a given byte value
+	Select(b byte) rgb
+	// Nearest returns the byte value corresponding to the approximate color
+	Nearest(c color.Color) byte
+}
+
+// Distancer is implemented by palettes that can say how far apart two
+// colors are, such as the built-in ones.
+type Distancer interface {
+	Distance(c1, c2 colorful.Color) float64
+}
+
+// Mode is the color support of a terminal.
+type Mode int
+
+const (
+	// TrueColor terminals take any 24-bit RGB color.
+	TrueColor Mode = iota
+	// Xterm256 terminals take the 256 xterm colors.
+	Xterm256
+	// ANSI16 terminals take the 8 standard and 8 bright ANSI colors.
+	ANSI16
+	// None is for terminals without color, or output that is not a terminal.
+	None
+)
+
+var modeNames = []string{"truecolor", "256", "16", "none"}
+
+func (m Mode) String() string {
+	if m < 0 || int(m) >= len(modeNames) {
+		return fmt.Sprintf("Mode(%d)", int(m))
+	}
+	return modeNames[m]
+}
+
+// ModeNames returns the names accepted by Parse, including "auto".
+func ModeNames() []string {
+	return append([]string{"auto"}, modeNames...)
+}
+
+// Parse returns the mode with the given name, as printed by Mode.String.
+// "auto" detects the mode of the current terminal.
+func Parse(name string) (Mode, error) {
+	name = strings.ToLower(name)
+	if name == "auto" {
+		return Detect(), nil
+	}
+	for i, n := range modeNames {
+		if n == name {
+			return Mode(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unrecognized color mode '%s', only %s are supported", name, strings.Join(ModeNames(), ", "))
+}
+
+// Detect guesses the color support of the terminal on standard output. It
+// is None when NO_COLOR is set or standard output is not a terminal, and
+// otherwise comes from COLORTERM, then the max_colors capability of TERM in
+// the terminfo database, then the name of TERM itself.
+func Detect() Mode {
+	if os.Getenv("NO_COLOR") != "" || !isTerminal(os.Stdout) {
+		return None
+	}
+	switch strings.ToLower(os.Getenv("COLORTERM")) {
+	case "truecolor", "24bit":
+		return TrueColor
+	}
+	term := os.Getenv("TERM")
+	if term == "" || term == "dumb" {
+		return None
+	}
+	if n, ok := maxColors(term); ok {
+		switch {
+		case n >= 1<<24:
+			return TrueColor
+		case n >= 256:
+			return Xterm256
+		case n >= 8:
+			return ANSI16
+		default:
+			return None
+		}
+	}
+	switch {
+	case strings.Contains(term, "truecolor") || strings.Contains(term, "direct"):
+		return TrueColor
+	case strings.Contains(term, "256"):
+		return Xterm256
+	default:
+		return ANSI16
+	}
+}
+
+// isTerminal reports whether f is a terminal, rather than a file, a pipe or
+// another character device such as /dev/null.
+func isTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
+
+// Terminal formats colored text for a terminal. A nil *Terminal formats
+// for truecolor terminals.
+type Terminal struct {
+	mode Mode
+	dist func(c1, c2 colorful.Color) float64
+
+	mu    sync.Mutex
+	cache map[rgb]int
+}
+
+// New returns a Terminal for the given mode. Colors are mapped to the nearest
+// color that the terminal supports using the Distance method of p, if it has
+// one, or else the distance in RGB. p may be nil.
+func New(mode Mode, p Palette) *Terminal {
+	t := &Terminal{
+		mode: mode,
+		dist: func(c1, c2 colorful.Color) float64 {
+			return c1.DistanceRgb(c2)
+		},
+		cache: make(map[rgb]int),
+	}
+	if d, ok := p.(Distancer); ok {
+		t.dist = d.Distance
+	}
+	return t
+}
+
+// Mode returns the mode of t.
+func (t *Terminal) Mode() Mode {
+	if t == nil {
+		return TrueColor
+	}
+	return t.mode
+}
+
+// Sprint returns s drawn in fg on bg.
+func (t *Terminal) Sprint(fg, bg rgb, s string) string {
+	if t.Mode() == None {
+		return s
+	}
+	return "\x1b[" + t.code(fg, false) + ";" + t.code(bg, true) + "m" + s + "\x1b[39;49m"
+}
+
+// SprintFg returns s drawn in fg on the default background.
+func (t *Terminal) SprintFg(fg rgb, s string) string {
+	if t.Mode() == None {
+		return s
+	}
+	return "\x1b[" + t.code(fg, false) + "m" + s + "\x1b[39m"
+}
+
+// SprintBg returns s drawn in the default foreground on bg.
+func (t *Terminal) SprintBg(bg rgb, s string) string {
+	if t.Mode() == None {
+		return s
+	}
+	return "\x1b[" + t.code(bg, true) + "m" + s + "\x1b[49m"
+}
+
+// code returns the SGR parameters that select c.
+func (t *Terminal) code(c rgb, background bool) string {
+	switch t.Mode() {
+	case Xterm256:
+		base := 38
+		if background {
+			base = 48
+		}
+		return fmt.Sprintf("%d;5;%d", base, t.index(c))
+	case ANSI16:
+		i := t.index(c)
+		base := 30
+		if i >= 8 {
+			base, i = 90, i-8
+		}
+		if background {
+			base += 10
+		}
+		return fmt.Sprintf("%d", base+i)
+	default:
+		base := 38
+		if background {
+			base = 48
+		}
+		return fmt.Sprintf("%d;2;%d;%d;%d", base, c[0], c[1], c[2])
+	}
+}
+
+// index returns the terminal color closest to c.
+func (t *Terminal) index(c rgb) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if i, ok := t.cache[c]; ok {
+		return i
+	}
+	colors, first := ansi16[:], 0
+	if t.mode == Xterm256 {
+		// The first 16 colors of the 256 are left out, since many
+		// terminals let users change them.
+		colors, first = xterm256[16:], 16
+	}
+	target := colorful.Color{R: float64(c[0]) / 255.0, G: float64(c[1]) / 255.0, B: float64(c[2]) / 255.0}
+	best, bestDist := 0, -1.0
+	for i, candidate := range colors {
+		d := t.dist(target, colorful.Color{R: float64(candidate[0]) / 255.0, G: float64(candidate[1]) / 255.0, B: float64(candidate[2]) / 255.0})
+		if bestDist < 0 || d < bestDist {
+			best, bestDist = i+first, d
+		}
+	}
+	t.cache[c] = best
+	return best
+}
+
+// ansi16 holds the colors of xterm's default 16-color palette.
+var ansi16 = [16]rgb{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0},
+	{0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0},
+	{92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+// xterm256 holds the 256 xterm colors: ansi16, a 6x6x6 color cube and a
+// ramp of 24 grays.
+var xterm256 = func() [256]rgb {
+	var result [256]rgb
+	copy(result[:], ansi16[:])
+	levels := [6]byte{0, 95, 135, 175, 215, 255}
+	for i := 0; i < 216; i++ {
+		result[16+i] = rgb{levels[i/36], levels[i/6%6], levels[i%6]}
+	}
+	for i := 0; i < 24; i++ {
+		v := byte(8 + 10*i)
+		result[232+i] = rgb{v, v, v}
+	}
+	return result
+}()
@@ -0,0 +1,107 @@
+package termcolor
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// Magic numbers of compiled terminfo files, with 16- and 32-bit numbers.
+	terminfoMagic   = 0432
+	terminfoMagic32 = 01036
+	// maxColorsIndex is the position of max_colors among the numbers.
+	maxColorsIndex = 13
+)
+
+// terminfoDirs returns the directories searched for terminfo entries, in the
+// same order as ncurses.
+func terminfoDirs() []string {
+	var dirs []string
+	if dir := os.Getenv("TERMINFO"); dir != "" {
+		dirs = append(dirs, dir)
+	}
+	if home := os.Getenv("HOME"); home != "" {
+		dirs = append(dirs, filepath.Join(home, ".terminfo"))
+	}
+	defaults := []string{"/etc/terminfo", "/lib/terminfo", "/usr/share/terminfo", "/usr/lib/terminfo"}
+	if list := os.Getenv("TERMINFO_DIRS"); list != "" {
+		for _, dir := range strings.Split(list, ":") {
+			if dir == "" {
+				dirs = append(dirs, defaults...)
+			} else {
+				dirs = append(dirs, dir)
+			}
+		}
+	} else {
+		dirs = append(dirs, defaults...)
+	}
+	return dirs
+}
+
+// maxColors returns the max_colors capability of term, and false if it
+// could not be found.
+func maxColors(term string) (int, bool) {
+	if term == "" || strings.ContainsAny(term, `/\`) {
+		return 0, false
+	}
+	for _, dir := range terminfoDirs() {
+		// Entries are filed under their first letter, or its hex code on
+		// case-insensitive file systems.
+		for _, sub := range []string{term[:1], fmt.Sprintf("%02x", term[0])} {
+			data, err := ioutil.ReadFile(filepath.Join(dir, sub, term))
+			if err != nil {
+				continue
+			}
+			n, err := parseMaxColors(data)
+			if err != nil {
+				return 0, false
+			}
+			return n, n >= 0
+		}
+	}
+	return 0, false
+}
+
+// parseMaxColors returns max_colors from a compiled terminfo entry, or -1 if
+// the entry does not have it. See term(5).
+func parseMaxColors(data []byte) (int, error) {
+	if len(data) < 12 {
+		return 0, fmt.Errorf("terminfo entry is too short")
+	}
+	var header [6]int
+	for i := range header {
+		header[i] = int(int16(binary.LittleEndian.Uint16(data[2*i:])))
+	}
+	size := 2
+	switch header[0] {
+	case terminfoMagic:
+	case terminfoMagic32:
+		size = 4
+	default:
+		return 0, fmt.Errorf("bad terminfo magic number %o", header[0])
+	}
+	namesSize, boolCount, numCount := header[1], header[2], header[3]
+	if namesSize < 0 || boolCount < 0 || numCount < 0 {
+		return 0, fmt.Errorf("bad terminfo header")
+	}
+	if numCount <= maxColorsIndex {
+		return -1, nil
+	}
+	offset := 12 + namesSize + boolCount
+	// Numbers start on an even byte.
+	if offset%2 != 0 {
+		offset++
+	}
+	offset += maxColorsIndex * size
+	if offset+size > len(data) {
+		return 0, fmt.Errorf("terminfo entry is too short")
+	}
+	if size == 2 {
+		return int(int16(binary.LittleEndian.Uint16(data[offset:]))), nil
+	}
+	return int(int32(binary.LittleEndian.Uint32(data[offset:]))), nil
+}
@@ -0,0 +1,42 @@
+package tester
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/chrisfenner/bytecolor/pkg/termcolor"
+)
+
+// Compare prints the 16x16 grids of two palettes side by side.
+// Cells for which marked is true are flagged with a '*'. Only the mode of
+// opts.Color is used: each grid gets a Terminal of its own, so that its
+// colors are approximated with its own palette's distances.
+func Compare(a, b Palette, marked [256]bool, opts *Options) {
+	grids := []Palette{a, b}
+	terms := make([]*termcolor.Terminal, len(grids))
+	if t := opts.color(); t != nil {
+		for g, p := range grids {
+			terms[g] = termcolor.New(t.Mode(), p)
+		}
+	}
+	for row := 0; row < 16; row++ {
+		for g, p := range grids {
+			if g != 0 {
+				fmt.Fprintf(opts.out(), "   ")
+			}
+			for col := 0; col < 16; col++ {
+				val := byte(row*16 + col)
+				msg := hex.EncodeToString([]byte{val})
+				if marked[val] {
+					msg += "*"
+				} else {
+					msg += " "
+				}
+				bg := p.Select(val)
+				fg := opts.text(bg)
+				fmt.Fprint(opts.out(), terms[g].Sprint(fg, bg, msg))
+			}
+		}
+		fmt.Fprintf(opts.out(), "\n")
+	}
+}
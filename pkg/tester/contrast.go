@@ -0,0 +1,87 @@
+package tester
+
+import (
+	"fmt"
+
+	"github.com/chrisfenner/bytecolor/pkg/contrast"
+)
+
+// maxContrastFailures is the number of unreadable bytes listed in the notes
+// of the contrast panel.
+const maxContrastFailures = 16
+
+func (o *Options) metric() contrast.Metric {
+	if o == nil {
+		return contrast.WCAG
+	}
+	return o.Metric
+}
+
+func (o *Options) minContrast() float64 {
+	if o == nil || o.MinContrast <= 0 {
+		return o.metric().Min()
+	}
+	return o.MinContrast
+}
+
+func (o *Options) textColors() []rgb {
+	if o == nil {
+		return nil
+	}
+	return o.TextColors
+}
+
+// text returns the color of labels drawn on bg.
+func (o *Options) text(bg rgb) rgb {
+	c, _ := o.metric().Best(bg, o.textColors())
+	return c
+}
+
+// contrastPanel shows every byte in a 16x16 grid, marking those on which no
+// text color reaches the required contrast.
+func contrastPanel(p Palette, opts *Options) (*Panel, error) {
+	x, _ := opts.size()
+	// Each cell is 2 characters wide, to hold hex values.
+	x /= 2
+	if x < 16 {
+		return nil, fmt.Errorf("panel width %d not big enough for test", x)
+	}
+	m, min := opts.metric(), opts.minContrast()
+	candidates := opts.textColors()
+	failures := m.Failures(p, min, candidates)
+	failed := make(map[byte]bool)
+	for _, f := range failures {
+		failed[f.Byte] = true
+	}
+
+	var cells []Cell
+	for i := 0; i < 256; i++ {
+		bg := p.Select(byte(i))
+		text, c := m.Best(bg, candidates)
+		cells = append(cells, Cell{
+			Value: byte(i),
+			Color: bg,
+			Label: !failed[byte(i)],
+			Mark:  failed[byte(i)],
+			Note:  fmt.Sprintf("best text #%02x%02x%02x, %s", text[0], text[1], text[2], m.Format(c)),
+		})
+	}
+	panel := &Panel{Title: fmt.Sprintf("%s text contrast", m), Labeled: true}
+	for row := 0; row < 16; row++ {
+		panel.Rows = append(panel.Rows, cells[row*16:(row+1)*16])
+	}
+
+	text := "black or white"
+	if candidates != nil {
+		text = fmt.Sprintf("any of %d text colors", len(candidates))
+	}
+	panel.Notes = append(panel.Notes, fmt.Sprintf("%d of 256 bytes have no text in %s reaching %s (marked **)", len(failures), text, m.Format(min)))
+	for i, f := range failures {
+		if i == maxContrastFailures {
+			panel.Notes = append(panel.Notes, fmt.Sprintf("and %d more", len(failures)-i))
+			break
+		}
+		panel.Notes = append(panel.Notes, fmt.Sprintf("0x%02x: best text #%02x%02x%02x, %s", f.Byte, f.Text[0], f.Text[1], f.Text[2], m.Format(f.Contrast)))
+	}
+	return panel, nil
+}
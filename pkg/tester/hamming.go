@@ -0,0 +1,115 @@
+package tester
+
+import (
+	"fmt"
+
+	"github.com/chrisfenner/bytecolor/pkg/deltae"
+	"github.com/chrisfenner/bytecolor/pkg/hamming"
+)
+
+// DefaultWorst is the number of pairs listed by the summary panel, when
+// Options.Worst is 0.
+const DefaultWorst = 16
+
+func (o *Options) minDeltaE() float64 {
+	if o == nil || o.MinDeltaE <= 0 {
+		return hamming.MinDeltaE
+	}
+	return o.MinDeltaE
+}
+
+func (o *Options) worst() int {
+	if o == nil || o.Worst <= 0 {
+		return DefaultWorst
+	}
+	return o.Worst
+}
+
+// groupRows lays out groups of cells side by side with a gap between them,
+// as many as fit in width cells.
+func groupRows(groups [][]Cell, width int) [][]Cell {
+	var rows [][]Cell
+	var row []Cell
+	for _, g := range groups {
+		if len(row) > 0 && len(row)+1+len(g) > width {
+			rows = append(rows, row)
+			row = nil
+		}
+		if len(row) > 0 {
+			row = append(row, Cell{Empty: true})
+		}
+		row = append(row, g...)
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// hammingNeighbors shows every byte followed by its eight one-bit neighbors, from
+// bit 0 to bit 7. Neighbors that are too close to the byte are marked.
+func hammingNeighbors(p Palette, opts *Options) (*Panel, error) {
+	x, _ := opts.size()
+	// Each cell is 2 characters wide, to hold hex values.
+	x /= 2
+	if x < 9 {
+		return nil, fmt.Errorf("panel width %d not big enough for test", x)
+	}
+	threshold := opts.minDeltaE()
+	flagged := 0
+	groups := make([][]Cell, 256)
+	for b := range groups {
+		val := byte(b)
+		color := p.Select(val)
+		group := []Cell{{Value: val, Color: color, Label: true}}
+		for bit := 0; bit < 8; bit++ {
+			n := val ^ (1 << bit)
+			d := deltae.RGB(color, p.Select(n))
+			c := Cell{
+				Value: n,
+				Color: p.Select(n),
+				Note:  fmt.Sprintf("bit %d of 0x%02x, dE %.2f", bit, val, d),
+			}
+			if d < threshold {
+				c.Mark = true
+				flagged++
+			}
+			group = append(group, c)
+		}
+		groups[b] = group
+	}
+	return &Panel{
+		Title:   "hamming neighbors",
+		Labeled: true,
+		Rows:    groupRows(groups, int(x)),
+		// Every pair is counted from both ends.
+		Notes: []string{fmt.Sprintf("%d of 1024 one-bit pairs have dE below %g (marked **)", flagged/2, threshold)},
+	}, nil
+}
+
+// hammingWorst shows the one-bit neighbor pairs with the closest colors.
+func hammingWorst(p Palette, opts *Options) (*Panel, error) {
+	x, _ := opts.size()
+	x /= 2
+	pairs := hamming.Pairs(p)
+	if n := opts.worst(); n < len(pairs) {
+		pairs = pairs[:n]
+	}
+	threshold := opts.minDeltaE()
+	panel := &Panel{Title: "closest hamming neighbors", Labeled: true}
+	var groups [][]Cell
+	for _, pair := range pairs {
+		mark := pair.DeltaE < threshold
+		note := pair.String()
+		groups = append(groups, []Cell{
+			{Value: pair.A, Color: p.Select(pair.A), Label: true, Mark: mark, Note: note},
+			{Value: pair.B, Color: p.Select(pair.B), Label: true, Mark: mark, Note: note},
+		})
+		if mark {
+			note += " *"
+		}
+		panel.Notes = append(panel.Notes, note)
+	}
+	panel.Rows = groupRows(groups, int(x))
+	return panel, nil
+}
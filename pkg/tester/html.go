@@ -0,0 +1,84 @@
+package tester
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+)
+
+var page = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Heading}}</title>
+<style>
+body { background: #1e1e1e; color: #e0e0e0; font-family: sans-serif; margin: 1em; }
+h2 { font-size: 1em; font-weight: normal; margin: 1.5em 0 0.5em; }
+pre { margin: 0.5em 0; }
+.row { display: flex; }
+.row span { flex: none; width: 6px; height: 6px; }
+.labeled .row span { width: 24px; height: 24px; font: 11px/24px monospace; text-align: center; }
+</style>
+</head>
+<body>
+<h1>{{.Heading}}</h1>
+{{range .Panels}}<section{{if .Labeled}} class="labeled"{{end}}>
+<h2>{{.Title}}</h2>
+{{range .Rows}}<div class="row">{{range .}}{{if .Empty}}<span></span>{{else}}<span style="background: {{.Background}}; color: {{.Foreground}}" title="{{.Title}}">{{.Text}}</span>{{end}}{{end}}</div>
+{{end}}{{if .Notes}}<pre>{{range .Notes}}{{.}}
+{{end}}</pre>
+{{end}}</section>
+{{end}}</body>
+</html>
+`))
+
+type htmlCell struct {
+	Background, Foreground template.CSS
+	Title, Text            string
+	Empty                  bool
+}
+
+type htmlPanel struct {
+	Title   string
+	Labeled bool
+	Rows    [][]htmlCell
+	Notes   []string
+}
+
+func cssColor(c rgb) template.CSS {
+	return template.CSS(fmt.Sprintf("#%02x%02x%02x", c[0], c[1], c[2]))
+}
+
+// WriteHTML writes the panels to w as a standalone HTML page, with the hex
+// value of every cell as its tooltip. Labels are colored as opts says.
+func WriteHTML(w io.Writer, heading string, panels []*Panel, opts *Options) error {
+	data := struct {
+		Heading string
+		Panels  []htmlPanel
+	}{Heading: heading}
+	for _, p := range panels {
+		hp := htmlPanel{Title: p.Title, Labeled: p.Labeled, Notes: p.Notes}
+		for _, row := range p.Rows {
+			var cells []htmlCell
+			for _, c := range row {
+				cell := htmlCell{
+					Background: cssColor(c.Color),
+					Foreground: cssColor(opts.text(c.Color)),
+					Title:      "0x" + c.Hex(),
+					Empty:      c.Empty,
+				}
+				if c.Note != "" {
+					cell.Title += ": " + c.Note
+				}
+				if p.Labeled {
+					cell.Text = strings.TrimSpace(c.Text())
+				}
+				cells = append(cells, cell)
+			}
+			hp.Rows = append(hp.Rows, cells)
+		}
+		data.Panels = append(data.Panels, hp)
+	}
+	return page.Execute(w, data)
+}
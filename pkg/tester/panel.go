@@ -0,0 +1,139 @@
+package tester
+
+import (
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"strings"
+
+	"github.com/chrisfenner/bytecolor/pkg/halfblock"
+)
+
+// Panel is the result of one test: rows of cells, which may differ in length.
+type Panel struct {
+	Title string
+	// Labeled panels have large cells showing the hex value of their byte.
+	// Other panels have one pixel per cell.
+	Labeled bool
+	Rows    [][]Cell
+	// Notes are lines of text shown after the panel.
+	Notes []string
+}
+
+// Cell is one cell of a panel.
+type Cell struct {
+	// Value is the byte whose color is shown.
+	Value byte
+	// Color is the palette color of Value.
+	Color rgb
+	// Label shows the hex value of the byte, on labeled panels.
+	Label bool
+	// Mark flags the cell as a problem. On labeled panels, marked cells
+	// without a label show "**".
+	Mark bool
+	// Note explains the cell, where there is room (e.g. in tooltips).
+	Note string
+	// Empty cells are gaps, drawn in the background color.
+	Empty bool
+}
+
+// Hex returns the hex value of the cell's byte.
+func (c Cell) Hex() string {
+	return hex.EncodeToString([]byte{c.Value})
+}
+
+// Text returns the two characters shown in the cell on labeled panels.
+func (c Cell) Text() string {
+	switch {
+	case c.Empty:
+		return "  "
+	case c.Label:
+		return c.Hex()
+	case c.Mark:
+		return "**"
+	default:
+		return "  "
+	}
+}
+
+// addValues adds rows of at most width cells for vals, starting on a new row.
+func (p *Panel) addValues(pal Palette, width int, vals []byte) {
+	for len(vals) > 0 {
+		n := width
+		if n > len(vals) {
+			n = len(vals)
+		}
+		row := make([]Cell, n)
+		for i, v := range vals[:n] {
+			row[i] = Cell{Value: v, Color: pal.Select(v)}
+		}
+		p.Rows = append(p.Rows, row)
+		vals = vals[n:]
+	}
+}
+
+// Width returns the length of the longest row.
+func (p *Panel) Width() int {
+	result := 0
+	for _, row := range p.Rows {
+		if len(row) > result {
+			result = len(row)
+		}
+	}
+	return result
+}
+
+// Image returns the panel with one pixel per cell. Past the end of short
+// rows, it is transparent.
+func (p *Panel) Image() *image.RGBA {
+	m := image.NewRGBA(image.Rect(0, 0, p.Width(), len(p.Rows)))
+	for y, row := range p.Rows {
+		for x, c := range row {
+			if c.Empty {
+				continue
+			}
+			m.SetRGBA(x, y, color.RGBA{c.Color[0], c.Color[1], c.Color[2], 255})
+		}
+	}
+	return m
+}
+
+// Print draws panels on the terminal (or opts.Out). Labeled panels take two
+// characters per cell, and others two cells per character (see halfblock).
+func Print(panels []*Panel, opts *Options) error {
+	w := opts.out()
+	for _, panel := range panels {
+		if !panel.Labeled {
+			if _, err := fmt.Fprintf(w, "\n"); err != nil {
+				return err
+			}
+			if err := halfblock.Write(w, panel.Image(), opts.color()); err != nil {
+				return err
+			}
+		} else {
+			var sb strings.Builder
+			for _, row := range panel.Rows {
+				sb.WriteString("\n")
+				for _, c := range row {
+					if c.Empty {
+						sb.WriteString(c.Text())
+						continue
+					}
+					sb.WriteString(opts.color().Sprint(opts.text(c.Color), c.Color, c.Text()))
+				}
+			}
+			sb.WriteString("\n")
+			if _, err := io.WriteString(w, sb.String()); err != nil {
+				return err
+			}
+		}
+		for _, note := range panel.Notes {
+			if _, err := fmt.Fprintf(w, "%s\n", note); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package tester
+
+import (
+	"fmt"
+
+	"github.com/chrisfenner/bytecolor/pkg/quantization"
+	"github.com/chrisfenner/bytecolor/pkg/ramp"
+)
+
+const (
+	// RampDeltaE is the quantization error drawn at the top of the ramp.
+	RampDeltaE = 20.0
+	// PoorDeltaE is the mean quantization error at which a region of color
+	// space is listed as poorly covered.
+	PoorDeltaE = 10.0
+	// maxPoorRegions is the number of poorly covered regions listed.
+	maxPoorRegions = 8
+)
+
+// quantizationPanel returns a panel builder that shows, for a plane of
+// colors, how far each moves when quantized by the palette.
+func quantizationPanel(plane quantization.Plane) PanelFunc {
+	return func(p Palette, opts *Options) (*Panel, error) {
+		x, y := opts.size()
+		if x < 16 || y < 16 {
+			return nil, fmt.Errorf("panel size (%d,%d) not big enough for test", x, y)
+		}
+		if y > 16 {
+			y = 16
+		}
+		// Each line of text holds two rows of pixels.
+		m, err := quantization.Sweep(p, &quantization.Options{
+			Plane:  plane,
+			Width:  int(x),
+			Height: int(2 * y),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		panel := &Panel{Title: fmt.Sprintf("quantization error (%s)", plane)}
+		for row := 0; row < m.Height; row++ {
+			cells := make([]Cell, m.Width)
+			for col := range cells {
+				i := row*m.Width + col
+				if !m.Valid(i) {
+					cells[col] = Cell{Empty: true}
+					continue
+				}
+				c := ramp.Heat(m.DeltaE[i] / RampDeltaE)
+				cells[col] = Cell{
+					Value: m.Nearest[i],
+					Color: rgb{c.R, c.G, c.B},
+					Note:  fmt.Sprintf("#%02x%02x%02x is drawn as 0x%02x, dE %.1f", m.Input[i][0], m.Input[i][1], m.Input[i][2], m.Nearest[i], m.DeltaE[i]),
+				}
+			}
+			panel.Rows = append(panel.Rows, cells)
+		}
+
+		mean, max := m.Stats()
+		panel.Notes = append(panel.Notes, fmt.Sprintf("dE from black (0) to white (%g): mean %.1f, max %.1f", RampDeltaE, mean, max))
+		poor := m.Poor(PoorDeltaE)
+		if len(poor) == 0 {
+			panel.Notes = append(panel.Notes, fmt.Sprintf("no region has a mean dE of %g or more", PoorDeltaE))
+		} else {
+			noun := "regions have"
+			if len(poor) == 1 {
+				noun = "region has"
+			}
+			panel.Notes = append(panel.Notes, fmt.Sprintf("%d %s a mean dE of %g or more:", len(poor), noun, PoorDeltaE))
+		}
+		for i, r := range poor {
+			if i == maxPoorRegions {
+				panel.Notes = append(panel.Notes, fmt.Sprintf("  ... and %d more", len(poor)-i))
+				break
+			}
+			panel.Notes = append(panel.Notes, "  "+r.String())
+		}
+		return panel, nil
+	}
+}
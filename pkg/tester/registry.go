@@ -0,0 +1,136 @@
+package tester
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/chrisfenner/bytecolor/pkg/quantization"
+)
+
+// PanelFunc builds one panel for a palette, at the size given by opts.
+type PanelFunc = func(p Palette, opts *Options) (*Panel, error)
+
+// PanelInfo describes a registered panel.
+type PanelInfo struct {
+	Name        string
+	Description string
+	Build       PanelFunc
+}
+
+var (
+	panelMu sync.RWMutex
+	// panels holds the registered panels, in the order they were registered.
+	panels []PanelInfo
+)
+
+func init() {
+	for _, info := range []PanelInfo{
+		{"numeric", "every byte value in numeric order", numericOrder},
+		{"hue", "every color sorted by hue, with grays first", hueOrder},
+		{"lightness", "every color sorted by lightness, then hue", lightnessOrder},
+		{"graycode", "a 16x16 gray code grid, where neighbors differ in one bit", grayCodeFill},
+		{"gamut", "the nearest palette colors to a sweep of grays and HSL colors", hslGamut},
+		{"popcount", "one row for each number of set bits", ones},
+		{"hamming", "every byte next to its eight one-bit neighbors, marking those that are too close", hammingNeighbors},
+		{"hamming-worst", "the one-bit neighbor pairs with the closest colors", hammingWorst},
+		{"quantization-hsl", "how far colors of an HSL plane move when quantized, and the worst regions", quantizationPanel(quantization.HSL)},
+		{"quantization-oklab", "how far colors of an OKLab plane move when quantized, and the worst regions", quantizationPanel(quantization.OKLab)},
+		{"contrast", "every byte in a 16x16 grid, marking those no text color is readable on", contrastPanel},
+	} {
+		if err := RegisterPanel(info.Name, info.Description, info.Build); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// RegisterPanel makes a panel available by name. Names are case-insensitive.
+// Registering the same name twice is an error.
+func RegisterPanel(name, description string, build PanelFunc) error {
+	panelMu.Lock()
+	defer panelMu.Unlock()
+	name = strings.ToLower(name)
+	if _, ok := lookupPanel(name); ok {
+		return fmt.Errorf("panel '%s' is already registered", name)
+	}
+	panels = append(panels, PanelInfo{
+		Name:        name,
+		Description: description,
+		Build:       build,
+	})
+	return nil
+}
+
+// RegisteredPanels returns all the registered panels, in the order they
+// were registered, which is the order they are drawn in by default.
+func RegisteredPanels() []PanelInfo {
+	panelMu.RLock()
+	defer panelMu.RUnlock()
+	return append([]PanelInfo(nil), panels...)
+}
+
+// PanelNames returns the names of all the registered panels, in order.
+func PanelNames() []string {
+	var result []string
+	for _, info := range RegisteredPanels() {
+		result = append(result, info.Name)
+	}
+	return result
+}
+
+func lookupPanel(name string) (PanelInfo, bool) {
+	for _, info := range panels {
+		if info.Name == name {
+			return info, true
+		}
+	}
+	return PanelInfo{}, false
+}
+
+// selectPanels returns the registered panels with the given names, in the
+// given order. No names means all of them.
+func selectPanels(names []string) ([]PanelInfo, error) {
+	if len(names) == 0 {
+		return RegisteredPanels(), nil
+	}
+	panelMu.RLock()
+	defer panelMu.RUnlock()
+	var result []PanelInfo
+	for _, name := range names {
+		info, ok := lookupPanel(strings.ToLower(strings.TrimSpace(name)))
+		if !ok {
+			var all []string
+			for _, info := range panels {
+				all = append(all, info.Name)
+			}
+			return nil, fmt.Errorf("unknown panel '%s', only %s are registered", name, strings.Join(all, ", "))
+		}
+		result = append(result, info)
+	}
+	return result, nil
+}
+
+// PanelError is the failure of one panel.
+type PanelError struct {
+	Panel string
+	Err   error
+}
+
+func (e *PanelError) Error() string {
+	return fmt.Sprintf("panel '%s': %v", e.Panel, e.Err)
+}
+
+func (e *PanelError) Unwrap() error {
+	return e.Err
+}
+
+// PanelErrors collects the failures of the panels in one run.
+type PanelErrors []*PanelError
+
+func (e PanelErrors) Error() string {
+	var lines []string
+	for _, err := range e {
+		lines = append(lines, err.Error())
+	}
+	return strings.Join(lines, "\n")
+}
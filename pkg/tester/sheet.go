@@ -0,0 +1,104 @@
+package tester
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"strings"
+
+	"github.com/chrisfenner/bytecolor/pkg/glyph"
+)
+
+const (
+	// sheetMargin is the space in pixels around and between the panels.
+	sheetMargin = 12
+	// pixelSize is the size in pixels of a cell of an unlabeled panel, and
+	// half the size of a cell of a labeled one, as on the terminal.
+	pixelSize = 5
+	// titleScale and headingScale are the font scales of panel titles and
+	// of the sheet's heading.
+	titleScale   = 2
+	headingScale = 3
+	// noteSpacing is the space in pixels between lines of notes.
+	noteSpacing = 3
+)
+
+var (
+	sheetBackground = color.RGBA{30, 30, 30, 255}
+	sheetText       = color.RGBA{224, 224, 224, 255}
+)
+
+// cellSize returns the size in pixels of the panel's cells on a sheet.
+func (p *Panel) cellSize() int {
+	if p.Labeled {
+		return 2 * pixelSize
+	}
+	return pixelSize
+}
+
+// ContactSheet draws the panels on one image, one below another, each under
+// its title, with heading at the top. Labels are colored as opts says.
+func ContactSheet(heading string, panels []*Panel, opts *Options) *image.RGBA {
+	_, headingHeight := glyph.Size(heading, headingScale)
+	_, titleHeight := glyph.Size("X", titleScale)
+	width, _ := glyph.Size(heading, headingScale)
+	height := sheetMargin + headingHeight
+	for _, p := range panels {
+		if w := p.Width() * p.cellSize(); w > width {
+			width = w
+		}
+		height += sheetMargin + titleHeight + sheetMargin/2 + len(p.Rows)*p.cellSize()
+		for _, note := range p.Notes {
+			w, h := glyph.Size(note, 1)
+			if w > width {
+				width = w
+			}
+			height += h + noteSpacing
+		}
+		if len(p.Notes) > 0 {
+			height += sheetMargin / 2
+		}
+	}
+	m := image.NewRGBA(image.Rect(0, 0, width+2*sheetMargin, height+sheetMargin))
+	draw.Draw(m, m.Rect, image.NewUniform(sheetBackground), image.Point{}, draw.Src)
+
+	glyph.Draw(m, sheetMargin, sheetMargin, heading, sheetText, headingScale)
+	y := sheetMargin + headingHeight
+	for _, p := range panels {
+		y += sheetMargin
+		glyph.Draw(m, sheetMargin, y, p.Title, sheetText, titleScale)
+		y += titleHeight + sheetMargin/2
+		size := p.cellSize()
+		for _, row := range p.Rows {
+			for i, c := range row {
+				if c.Empty {
+					continue
+				}
+				x := sheetMargin + i*size
+				r := image.Rect(x, y, x+size, y+size)
+				draw.Draw(m, r, image.NewUniform(color.RGBA{c.Color[0], c.Color[1], c.Color[2], 255}), image.Point{}, draw.Src)
+				if text := strings.TrimSpace(c.Text()); p.Labeled && text != "" {
+					fg := opts.text(c.Color)
+					w, h := glyph.Size(text, 1)
+					glyph.Draw(m, x+(size-w)/2, y+(size-h)/2, text, color.RGBA{fg[0], fg[1], fg[2], 255}, 1)
+				}
+			}
+			y += size
+		}
+		if len(p.Notes) > 0 {
+			y += sheetMargin / 2
+		}
+		for _, note := range p.Notes {
+			glyph.Draw(m, sheetMargin, y, note, sheetText, 1)
+			y += glyph.Height + noteSpacing
+		}
+	}
+	return m
+}
+
+// WritePNG writes the ContactSheet of the panels to w as a PNG.
+func WritePNG(w io.Writer, heading string, panels []*Panel, opts *Options) error {
+	return png.Encode(w, ContactSheet(heading, panels, opts))
+}
@@ -1,19 +1,17 @@
 package tester
 
 import (
-	"encoding/hex"
 	"fmt"
 	"image/color"
+	"io"
 	"math"
+	"os"
 	"sort"
 
+	"github.com/chrisfenner/bytecolor/pkg/contrast"
+	"github.com/chrisfenner/bytecolor/pkg/termcolor"
 	"github.com/lucasb-eyer/go-colorful"
-	terminal "github.com/wayneashleyberry/terminal-dimensions"
-	tc "github.com/wayneashleyberry/truecolor/pkg/color"
-)
-
-var (
-	tooCloseSquared = float64(8000)
+	"golang.org/x/term"
 )
 
 type rgb = [3]byte
@@ -25,29 +23,102 @@ type Palette interface {
 	Nearest(c color.Color) byte
 }
 
-func nearestColor(p Palette, c color.Color) rgb {
-	return p.Select(p.Nearest(c))
+// DefaultWidth and DefaultHeight are the size of the panels when it is not
+// given and cannot be read from the terminal.
+const (
+	DefaultWidth  = 80
+	DefaultHeight = 24
+)
+
+// Options controls how the panels are drawn. nil gives the defaults.
+type Options struct {
+	// Color formats the colors for the terminal. nil means truecolor.
+	Color *termcolor.Terminal
+	// Out is where the panels are written. nil means standard output.
+	Out io.Writer
+	// Width and Height are the size of the panels in characters. 0 means
+	// the size of the terminal if TerminalSize is set and there is one, or
+	// else DefaultWidth and DefaultHeight.
+	Width, Height int
+	// TerminalSize asks the terminal for its size when Width or Height is 0.
+	// Without it, the panels are the same size wherever they are drawn.
+	TerminalSize bool
+	// Panels names the registered panels to draw, in order. nil means all
+	// of them, in the order they were registered.
+	Panels []string
+	// MinDeltaE is the CIEDE2000 difference below which one-bit neighbors
+	// are flagged. 0 means hamming.MinDeltaE.
+	MinDeltaE float64
+	// Worst is the number of closest neighbor pairs to list. 0 means
+	// DefaultWorst.
+	Worst int
+	// Metric measures the contrast of text on the palette colors. Labels are
+	// drawn in whichever of TextColors contrasts most under it.
+	Metric contrast.Metric
+	// TextColors are the candidate colors for labels. nil means black and
+	// white; contrast.Colors gives the palette's own colors.
+	TextColors []rgb
+	// MinContrast is the contrast below which text is flagged as
+	// unreadable. 0 means the minimum for body text under Metric.
+	MinContrast float64
 }
 
-func invert(c rgb) rgb {
-	res := [3]byte{255 - c[0], 255 - c[1], 255 - c[2]}
-	// For certain medium grays, the inverted color is not distinct
-	// Print white in these cases
-	distance := distanceSquared(c, res)
-	if distance < tooCloseSquared {
-		res = [3]byte{255, 255, 255}
-	}
-	return res
+func (o *Options) color() *termcolor.Terminal {
+	if o == nil {
+		return nil
+	}
+	return o.Color
 }
 
-func distanceSquared(a, b rgb) float64 {
-	res := float64(0)
+func (o *Options) out() io.Writer {
+	if o == nil || o.Out == nil {
+		return os.Stdout
+	}
+	return o.Out
+}
 
-	for i := 0; i < 3; i++ {
-		res += (float64(a[i]) - float64(b[i])) * (float64(a[i]) - float64(b[i]))
+// size returns the width and height of the panels.
+func (o *Options) size() (uint, uint) {
+	var w, h int
+	if o != nil {
+		w, h = o.Width, o.Height
+	}
+	if o != nil && o.TerminalSize && (w <= 0 || h <= 0) {
+		if tw, th, err := term.GetSize(int(os.Stdout.Fd())); err == nil {
+			if w <= 0 {
+				w = tw
+			}
+			if h <= 0 {
+				h = th
+			}
+		}
+	}
+	if w <= 0 {
+		w = DefaultWidth
+	}
+	if h <= 0 {
+		h = DefaultHeight
 	}
+	return uint(w), uint(h)
+}
+
+func nearestColor(p Palette, c color.Color) rgb {
+	return p.Select(p.Nearest(c))
+}
 
-	return res
+// hclOrder returns the byte values of p, sorted by less on their colors.
+func hclOrder(p Palette, less func(a, b colorful.Color) bool) []byte {
+	colors := make([]colorful.Color, 256)
+	vals := make([]byte, 256)
+	for i := range colors {
+		rgb := p.Select(byte(i))
+		colors[i], _ = colorful.MakeColor(color.RGBA{rgb[0], rgb[1], rgb[2], 255})
+		vals[i] = byte(i)
+	}
+	sort.SliceStable(vals, func(i, j int) bool {
+		return less(colors[vals[i]], colors[vals[j]])
+	})
+	return vals
 }
 
 func grayCode(row, column uint) byte {
@@ -81,33 +152,49 @@ func grayCode(row, column uint) byte {
 	return result
 }
 
-func Test(p Palette) error {
-	if err := numericOrder(p); err != nil {
+// Test draws the panels selected by opts for p on the terminal. A panel that
+// fails does not stop the others; the failures are returned as PanelErrors.
+func Test(p Palette, opts *Options) error {
+	panels, err := Panels(p, opts)
+	if err := Print(panels, opts); err != nil {
 		return err
 	}
-	if err := hueOrder(p); err != nil {
-		return err
-	}
-	if err := lightnessOrder(p); err != nil {
-		return err
-	}
-	if err := grayCodeFill(p); err != nil {
-		return err
-	}
-	if err := hslGamut(p); err != nil {
-		return err
-	}
-	if err := ones(p); err != nil {
-		return err
-	}
-	return nil
+	return err
 }
 
-func ones(p Palette) error {
-	x, err := terminal.Width()
+// Panels builds the panels selected by opts for p. The panels that could be
+// built are returned even if others failed, whose failures are returned as
+// PanelErrors.
+func Panels(p Palette, opts *Options) ([]*Panel, error) {
+	var names []string
+	if opts != nil {
+		names = opts.Panels
+	}
+	infos, err := selectPanels(names)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	var result []*Panel
+	var errs PanelErrors
+	for _, info := range infos {
+		panel, err := info.Build(p, opts)
+		if err != nil {
+			errs = append(errs, &PanelError{Panel: info.Name, Err: err})
+			continue
+		}
+		if panel.Title == "" {
+			panel.Title = info.Name
+		}
+		result = append(result, panel)
 	}
+	if errs != nil {
+		return result, errs
+	}
+	return result, nil
+}
+
+func ones(p Palette, opts *Options) (*Panel, error) {
+	x, _ := opts.size()
 	vals := make([]byte, 256)
 	for i := range vals {
 		vals[i] = byte(i)
@@ -125,17 +212,11 @@ func ones(p Palette) error {
 		ones := countOnes(val)
 		rows[ones] = append(rows[ones], val)
 	}
+	panel := &Panel{Title: "popcount rows"}
 	for _, row := range rows {
-		for i, val := range row {
-			if i%int(x) == 0 {
-				fmt.Printf("\n")
-			}
-			bg := p.Select(val)
-			tc.Background(byte(bg[0]), byte(bg[1]), byte(bg[2])).Print(" ")
-		}
+		panel.addValues(p, int(x), row)
 	}
-	fmt.Printf("\n")
-	return nil
+	return panel, nil
 }
 
 func countOnes(b byte) int {
@@ -148,20 +229,13 @@ func countOnes(b byte) int {
 	return ones
 }
 
-func grayCodeFill(p Palette) error {
-	// Get the current console width and height for tiling.
+func grayCodeFill(p Palette, opts *Options) (*Panel, error) {
+	// Get the console width and height for tiling.
 	// Each cell will be 2 characters wide, to hold hex values.
-	x, err := terminal.Width()
-	if err != nil {
-		return err
-	}
+	x, y := opts.size()
 	x /= 2
-	y, err := terminal.Height()
-	if err != nil {
-		return err
-	}
 	if x < 16 || y < 16 {
-		return fmt.Errorf("detected terminal size (%d,%d) not big enough for test", x, y)
+		return nil, fmt.Errorf("panel size (%d,%d) not big enough for test", x, y)
 	}
 	if y > 20 {
 		y = 20
@@ -171,97 +245,78 @@ func grayCodeFill(p Palette) error {
 	lPad := (x - 16) / 2
 	uPad := (y - 16) / 2
 
+	panel := &Panel{Title: "gray code fill", Labeled: true}
 	for i := uint(0); i < y; i++ {
-		fmt.Printf("\n")
-		for j := uint(0); j < x; j++ {
+		row := make([]Cell, x)
+		for j := range row {
+			j := uint(j)
 			// Shift the code values left/up by the padding
 			code := grayCode((i+16-uPad)%16, (j + 16 - lPad))
-			msg := "  "
-			// If we are filling in the center 16x16 square, print a value
-			if i >= uPad && i < (uPad+16) && j >= lPad && j < (lPad+16) {
-				msg = hex.EncodeToString([]byte{code})
+			row[j] = Cell{
+				Value: code,
+				Color: p.Select(code),
+				// If we are filling in the center 16x16 square, print a value
+				Label: i >= uPad && i < (uPad+16) && j >= lPad && j < (lPad+16),
 			}
-			bg := p.Select(code)
-			fg := invert(bg)
-			tc.Color(fg[0], fg[1], fg[2]).Background(bg[0], bg[1], bg[2]).Print(msg)
 		}
+		panel.Rows = append(panel.Rows, row)
 	}
-	fmt.Printf("\n")
-	return nil
+	return panel, nil
 }
 
-func hslGamut(p Palette) error {
-	// Get the current console width and height for tiling.
-	x, err := terminal.Width()
-	if err != nil {
-		return err
-	}
-	y, err := terminal.Height()
-	if err != nil {
-		return err
-	}
+func hslGamut(p Palette, opts *Options) (*Panel, error) {
+	// Get the console width and height for tiling.
+	x, y := opts.size()
 	if x < 16 || y < 16 {
-		return fmt.Errorf("detected terminal size (%d,%d) not big enough for test", x, y)
+		return nil, fmt.Errorf("panel size (%d,%d) not big enough for test", x, y)
 	}
 	if y > 16 {
 		y = 16
 	}
+	// Each line of text holds two rows of pixels.
+	y *= 2
 
 	// Grayscale across x
-	fmt.Printf("\n")
-	for j := uint(0); j < x; j++ {
+	gray := make([]byte, x)
+	for j := range gray {
 		l := 1.0 / float64(x) * float64(j)
-		c := colorful.Hsl(0.0, 0.0, l)
-		bg := nearestColor(p, c)
-		tc.Background(bg[0], bg[1], bg[2]).Print(" ")
+		gray[j] = p.Nearest(colorful.Hsl(0.0, 0.0, l))
 	}
+	panel := &Panel{Title: "hsl gamut"}
+	panel.addValues(p, int(x), gray)
+	panel.addValues(p, int(x), gray)
 	// Draw an HSL rectangle
 	for i := uint(0); i <= y; i++ {
-		fmt.Printf("\n")
-		for j := uint(0); j < x; j++ {
+		row := make([]byte, x)
+		for j := range row {
 			// Every x is a step around the hue circle
 			// Every y is a step in the lightness
 			// Saturation = 1.00
 			h := 360.0 / float64(x) * float64(j)
 			l := 1.0 / float64(y) * float64(i)
-			c := colorful.Hsl(h, 1.0, l)
-			bg := nearestColor(p, c)
-			tc.Background(bg[0], bg[1], bg[2]).Print(" ")
+			row[j] = p.Nearest(colorful.Hsl(h, 1.0, l))
 		}
+		panel.addValues(p, int(x), row)
 	}
-	fmt.Printf("\n")
-	return nil
+	return panel, nil
 }
 
-func numericOrder(p Palette) error {
-	x, err := terminal.Width()
-	if err != nil {
-		return err
-	}
-	for i := 0; i < 256; i++ {
-		if i%int(x) == 0 {
-			fmt.Printf("\n")
-		}
-		bg := p.Select(byte(i))
-		tc.Background(bg[0], bg[1], bg[2]).Print(" ")
+func numericOrder(p Palette, opts *Options) (*Panel, error) {
+	x, _ := opts.size()
+	vals := make([]byte, 256)
+	for i := range vals {
+		vals[i] = byte(i)
 	}
-	fmt.Printf("\n")
-	return nil
+	panel := &Panel{Title: "numeric order"}
+	panel.addValues(p, int(x), vals)
+	return panel, nil
 }
 
-func hueOrder(p Palette) error {
-	x, err := terminal.Width()
-	if err != nil {
-		return err
-	}
-	colors := make([]colorful.Color, 256)
-	for i := range colors {
-		rgb := p.Select(byte(i))
-		colors[i], _ = colorful.MakeColor(color.RGBA{rgb[0], rgb[1], rgb[2], 255})
-	}
-	sort.Slice(colors, func(i, j int) bool {
-		hi, ci, li := colors[i].Hcl()
-		hj, cj, lj := colors[j].Hcl()
+func hueOrder(p Palette, opts *Options) (*Panel, error) {
+	x, _ := opts.size()
+	vals := hclOrder(p, func(a, b colorful.Color) bool {
+		hi, ci, li := a.Hcl()
+		hj, cj, lj := b.Hcl()
 		const minC = 0.1
 		// If one color is very un-colorful, put it first.
 		if ci < minC && cj >= minC {
@@ -277,30 +332,16 @@ func hueOrder(p Palette) error {
 		// If neither color is un-colorful, order by hue.
 		return hi < hj
 	})
-	for i, c := range colors {
-		if i%int(x) == 0 {
-			fmt.Printf("\n")
-		}
-		r, g, b, _ := c.RGBA()
-		tc.Background(byte(r/256), byte(g/256), byte(b/256)).Print(" ")
-	}
-	fmt.Printf("\n")
-	return nil
+	panel := &Panel{Title: "hue order"}
+	panel.addValues(p, int(x), vals)
+	return panel, nil
 }
 
-func lightnessOrder(p Palette) error {
-	x, err := terminal.Width()
-	if err != nil {
-		return err
-	}
-	colors := make([]colorful.Color, 256)
-	for i := range colors {
-		rgb := p.Select(byte(i))
-		colors[i], _ = colorful.MakeColor(color.RGBA{rgb[0], rgb[1], rgb[2], 255})
-	}
-	sort.Slice(colors, func(i, j int) bool {
-		hi, ci, li := colors[i].Hcl()
-		hj, cj, lj := colors[j].Hcl()
+func lightnessOrder(p Palette, opts *Options) (*Panel, error) {
+	x, _ := opts.size()
+	vals := hclOrder(p, func(a, b colorful.Color) bool {
+		hi, ci, li := a.Hcl()
+		hj, cj, lj := b.Hcl()
 		const minC = 0.1
 		// If one color is very un-colorful, put it first.
 		if ci < minC && cj >= minC {
@@ -321,13 +362,7 @@ func lightnessOrder(p Palette) error {
 		// If both colors have very close lightness, order by hue.
 		return hi < hj
 	})
-	for i, c := range colors {
-		if i%int(x) == 0 {
-			fmt.Printf("\n")
-		}
-		r, g, b, _ := c.RGBA()
-		tc.Background(byte(r), byte(g/256), byte(b/256)).Print(" ")
-	}
-	fmt.Printf("\n")
-	return nil
+	panel := &Panel{Title: "lightness order"}
+	panel.addValues(p, int(x), vals)
+	return panel, nil
 }
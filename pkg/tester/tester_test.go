@@ -0,0 +1,134 @@
+package tester
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/chrisfenner/bytecolor/pkg/contrast"
+	"github.com/chrisfenner/bytecolor/pkg/glyph"
+	"github.com/chrisfenner/bytecolor/pkg/registry"
+	"github.com/chrisfenner/bytecolor/pkg/termcolor"
+)
+
+// gray is a palette in which every byte is the same mid-tone, on which WCAG
+// 2 picks black text and APCA white.
+type gray struct{}
+
+func (gray) Select(b byte) rgb          { return rgb{0x80, 0x80, 0x80} }
+func (gray) Nearest(c color.Color) byte { return 0 }
+
+func TestLabelsFollowMetric(t *testing.T) {
+	panel := &Panel{Title: "label", Labeled: true, Rows: [][]Cell{{{Value: 0x5a, Color: rgb{0x80, 0x80, 0x80}, Label: true}}}}
+	for _, tc := range []struct {
+		name     string
+		opts     *Options
+		expected rgb
+	}{
+		{"default", &Options{}, contrast.Black},
+		{"wcag", &Options{Metric: contrast.WCAG}, contrast.Black},
+		{"apca", &Options{Metric: contrast.APCA}, contrast.White},
+		{"text colors", &Options{TextColors: []rgb{{0x80, 0x80, 0x80}, {0x00, 0x00, 0xff}}}, rgb{0x00, 0x00, 0xff}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			tc.opts.Out = &buf
+			if err := Print([]*Panel{panel}, tc.opts); err != nil {
+				t.Fatalf("Print() = %v", err)
+			}
+			fg := fmt.Sprintf("38;2;%d;%d;%d", tc.expected[0], tc.expected[1], tc.expected[2])
+			if !strings.Contains(buf.String(), fg) {
+				t.Errorf("Print() = %q, expected foreground %s", buf.String(), fg)
+			}
+
+			buf.Reset()
+			if err := WriteHTML(&buf, "labels", []*Panel{panel}, tc.opts); err != nil {
+				t.Fatalf("WriteHTML() = %v", err)
+			}
+			css := fmt.Sprintf("color: #%02x%02x%02x", tc.expected[0], tc.expected[1], tc.expected[2])
+			if !strings.Contains(buf.String(), css) {
+				t.Errorf("WriteHTML() has no %q", css)
+			}
+
+			m := ContactSheet("labels", []*Panel{panel}, tc.opts)
+			found := false
+			for i := 0; i < len(m.Pix) && !found; i += 4 {
+				found = m.Pix[i] == tc.expected[0] && m.Pix[i+1] == tc.expected[1] && m.Pix[i+2] == tc.expected[2]
+			}
+			if !found {
+				t.Errorf("ContactSheet() has no label in %v", tc.expected)
+			}
+
+			buf.Reset()
+			Compare(gray{}, gray{}, [256]bool{}, tc.opts)
+			if !strings.Contains(buf.String(), fg) {
+				t.Errorf("Compare() has no foreground %s", fg)
+			}
+		})
+	}
+}
+
+// TestPanelsWithoutTerminal draws every registered panel into a buffer, as
+// under go test or CI, where there is no terminal to ask for a size.
+func TestPanelsWithoutTerminal(t *testing.T) {
+	p, err := registry.New("hcl")
+	if err != nil {
+		t.Fatalf("registry.New() = %v", err)
+	}
+	var buf bytes.Buffer
+	opts := &Options{Out: &buf, Width: 80, Height: 24, Color: termcolor.New(termcolor.None, p)}
+	panels, err := Panels(p, opts)
+	if err != nil {
+		t.Fatalf("Panels() = %v", err)
+	}
+	if got, want := len(panels), len(RegisteredPanels()); got != want {
+		t.Fatalf("Panels() drew %d panels, expected %d", got, want)
+	}
+	for _, panel := range panels {
+		if w := panel.Width(); w == 0 || w > 80 {
+			t.Errorf("panel %s is %d cells wide, expected 1 to 80", panel.Title, w)
+		}
+	}
+	if err := Print(panels, opts); err != nil {
+		t.Fatalf("Print() = %v", err)
+	}
+	if buf.Len() == 0 {
+		t.Errorf("Print() wrote nothing")
+	}
+}
+
+func TestSheetTextHasGlyphs(t *testing.T) {
+	metrics := []contrast.Metric{contrast.WCAG, contrast.APCA}
+	for i, name := range registry.Names() {
+		p, err := registry.New(name)
+		if err != nil {
+			t.Fatalf("registry.New(%q) = %v", name, err)
+		}
+		// Alternate the metrics, which format contrasts differently, and ask
+		// for so much contrast that the contrast panel lists failures.
+		m := metrics[i%len(metrics)]
+		opts := &Options{Out: ioutil.Discard, Width: 80, Height: 24, Metric: m, MinContrast: 100}
+		panels, err := Panels(p, opts)
+		if err != nil {
+			t.Fatalf("Panels(%s) = %v", name, err)
+		}
+		text := []string{fmt.Sprintf("palette %s", name)}
+		for _, panel := range panels {
+			text = append(text, panel.Title)
+			text = append(text, panel.Notes...)
+			for _, row := range panel.Rows {
+				for _, c := range row {
+					text = append(text, c.Text())
+				}
+			}
+		}
+		for _, s := range text {
+			if !glyph.Has(s) {
+				t.Errorf("%s, %s: %q has characters without glyphs", name, m, s)
+			}
+		}
+	}
+}
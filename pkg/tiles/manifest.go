@@ -0,0 +1,99 @@
+package tiles
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// manifestName is the file next to the levels of tiles that records what
+// they were generated from.
+const manifestName = "manifest.json"
+
+// sampleSize is the number of bytes at each end of the file that the
+// manifest hashes, to tell apart files of the same size.
+const sampleSize = 64 * 1024
+
+// manifest records everything the tiles depend on, so that a run is only
+// resumed over tiles that it would have drawn the same way.
+type manifest struct {
+	Size      int64  `json:"size"`
+	Width     int    `json:"width"`
+	TileSize  int    `json:"tileSize"`
+	Aggregate string `json:"aggregate"`
+	// Palette is the SHA-256 of the palette's 256 colors.
+	Palette string `json:"palette"`
+	// Sample is the SHA-256 of the first and last sampleSize bytes of the
+	// file, which a different file of the same size is unlikely to share.
+	Sample string `json:"sample"`
+}
+
+func newManifest(r io.ReaderAt, py *Pyramid, p Palette) (manifest, error) {
+	h := sha256.New()
+	for i := 0; i < 256; i++ {
+		c := p.Select(byte(i))
+		h.Write(c[:])
+	}
+	palette := hex.EncodeToString(h.Sum(nil))
+
+	h.Reset()
+	head := py.size
+	if head > sampleSize {
+		head = sampleSize
+	}
+	tail := py.size - sampleSize
+	if tail < head {
+		tail = head
+	}
+	for _, s := range []*io.SectionReader{io.NewSectionReader(r, 0, head), io.NewSectionReader(r, tail, py.size-tail)} {
+		if _, err := io.Copy(h, s); err != nil {
+			return manifest{}, err
+		}
+	}
+	return manifest{
+		Size:      py.size,
+		Width:     py.width,
+		TileSize:  py.tileSize,
+		Aggregate: py.aggregate.String(),
+		Palette:   palette,
+		Sample:    hex.EncodeToString(h.Sum(nil)),
+	}, nil
+}
+
+// checkManifest makes sure that the tiles under root, if any, were generated
+// from the same file, with the same palette and options as m, and records m for the
+// next run.
+func checkManifest(root string, m manifest) error {
+	path := filepath.Join(root, manifestName)
+	data, err := ioutil.ReadFile(path)
+	switch {
+	case err == nil:
+		var old manifest
+		if err := json.Unmarshal(data, &old); err != nil {
+			return fmt.Errorf("reading %s: %w", path, err)
+		}
+		if old != m {
+			return fmt.Errorf("the tiles in %s were generated from a different file, palette or options (%+v, not %+v); remove them or use another directory", root, old, m)
+		}
+		return nil
+	case !os.IsNotExist(err):
+		return err
+	}
+	// Tiles without a manifest could have been drawn any way at all.
+	if entries, err := ioutil.ReadDir(root); err == nil && len(entries) > 0 {
+		return fmt.Errorf("%s already holds tiles, but no %s to say how they were generated; remove them or use another directory", root, manifestName)
+	}
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return err
+	}
+	data, err = json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(data, '\n'), 0644)
+}
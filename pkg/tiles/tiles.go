@@ -0,0 +1,357 @@
+// Package tiles cuts the rendering of a binary file into a pyramid of
+// fixed-size tiles, for viewers that pan and zoom over very large files.
+//
+// The full-resolution image is row-major, one byte per pixel. Each level
+// below it halves the width and height, so that a pixel at level
+// MaxLevel-k summarizes a 2^k by 2^k block of bytes.
+package tiles
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+
+	"github.com/chrisfenner/bytecolor/pkg/render"
+)
+
+type rgb = [3]byte
+
+type Palette interface {
+	// Select returns 8bpc R,G,B values for a given byte value
+	Select(b byte) rgb
+	// Nearest returns the byte value corresponding to the approximate color
+	Nearest(c color.Color) byte
+}
+
+// Format is the directory layout of the generated tiles.
+type Format int
+
+const (
+	// XYZ writes tiles to <dir>/<level>/<x>/<y>.png. Edge tiles are padded
+	// with transparent pixels to the full tile size.
+	XYZ Format = iota
+	// DeepZoom writes tiles to <dir>/<name>_files/<level>/<x>_<y>.png next to
+	// a <dir>/<name>.dzi descriptor. Edge tiles are cropped.
+	DeepZoom
+)
+
+// Options controls the pyramid. Width is required.
+type Options struct {
+	// Width is the number of bytes per row at full resolution.
+	Width int
+	// TileSize is the width and height of a tile. 0 means 256.
+	TileSize int
+	// Format is the directory layout of the tiles.
+	Format Format
+	// Name is the base name of the Deep Zoom descriptor. "" means "tiles".
+	Name string
+	// Aggregate selects how lower-resolution pixels summarize their bytes.
+	Aggregate render.Aggregate
+	// Workers is the number of tiles generated in parallel. 0 means one per CPU.
+	Workers int
+	// Progress, if not nil, is called after each tile with the number of
+	// tiles done (including ones skipped because they already existed) and
+	// the total number of tiles. It may be called from several goroutines.
+	Progress func(done, total int)
+}
+
+// Pyramid describes the levels of tiles for a file.
+type Pyramid struct {
+	size      int64
+	width     int
+	height    int64
+	tileSize  int
+	maxLevel  int
+	aggregate render.Aggregate
+}
+
+// New returns the pyramid for a file of the given size.
+func New(size int64, opts *Options) (*Pyramid, error) {
+	if opts == nil || opts.Width <= 0 {
+		return nil, fmt.Errorf("width must be positive")
+	}
+	if size <= 0 {
+		return nil, fmt.Errorf("cannot tile an empty file")
+	}
+	tileSize := opts.TileSize
+	if tileSize < 0 {
+		return nil, fmt.Errorf("tile size must not be negative")
+	}
+	if tileSize == 0 {
+		tileSize = 256
+	}
+	py := &Pyramid{
+		size:      size,
+		width:     opts.Width,
+		height:    (size + int64(opts.Width) - 1) / int64(opts.Width),
+		tileSize:  tileSize,
+		aggregate: opts.Aggregate,
+	}
+	for int64(1)<<py.maxLevel < int64(py.width) || int64(1)<<py.maxLevel < py.height {
+		py.maxLevel++
+	}
+	return py, nil
+}
+
+// MaxLevel returns the level with one byte per pixel. Level 0 is a single pixel.
+func (py *Pyramid) MaxLevel() int {
+	return py.maxLevel
+}
+
+// TileSize returns the width and height of a (non-edge) tile.
+func (py *Pyramid) TileSize() int {
+	return py.tileSize
+}
+
+// Factor returns the width (and height) in bytes of a pixel at the given level.
+func (py *Pyramid) Factor(level int) int64 {
+	return int64(1) << (py.maxLevel - level)
+}
+
+// LevelSize returns the size in pixels of the whole image at the given level.
+func (py *Pyramid) LevelSize(level int) (int, int) {
+	f := py.Factor(level)
+	return int((int64(py.width) + f - 1) / f), int((py.height + f - 1) / f)
+}
+
+// Tiles returns the number of tiles across and down at the given level.
+func (py *Pyramid) Tiles(level int) (int, int) {
+	w, h := py.LevelSize(level)
+	return (w + py.tileSize - 1) / py.tileSize, (h + py.tileSize - 1) / py.tileSize
+}
+
+// Offset returns the file offset of the first byte under the pixel at (x, y)
+// of the given level, or false if there is no byte there.
+func (py *Pyramid) Offset(level, x, y int) (int64, bool) {
+	f := py.Factor(level)
+	col, row := int64(x)*f, int64(y)*f
+	if x < 0 || y < 0 || col >= int64(py.width) {
+		return 0, false
+	}
+	offset := row*int64(py.width) + col
+	return offset, offset < py.size
+}
+
+// Tile renders the tile at (x, y) of the given level. Pixels with no bytes
+// under them are transparent; if crop is set, the tile is instead cropped to
+// the edge of the image.
+func (py *Pyramid) Tile(r io.ReaderAt, p Palette, level, x, y int, crop bool) (*image.NRGBA, error) {
+	if level < 0 || level > py.maxLevel {
+		return nil, fmt.Errorf("level %d is outside [0, %d]", level, py.maxLevel)
+	}
+	across, down := py.Tiles(level)
+	if x < 0 || y < 0 || x >= across || y >= down {
+		return nil, fmt.Errorf("tile (%d, %d) is outside level %d", x, y, level)
+	}
+	levelW, levelH := py.LevelSize(level)
+	w, h := py.tileSize, py.tileSize
+	if crop {
+		if rest := levelW - x*py.tileSize; rest < w {
+			w = rest
+		}
+		if rest := levelH - y*py.tileSize; rest < h {
+			h = rest
+		}
+	}
+	m := image.NewNRGBA(image.Rect(0, 0, w, h))
+
+	f := py.Factor(level)
+	c := render.NewColorizer(p, py.aggregate)
+	summaries := make([]render.Summary, w)
+	firstCol := int64(x*py.tileSize) * f
+	if firstCol >= int64(py.width) {
+		return m, nil
+	}
+	// Read whole rows of bytes under the tile, one row of pixels at a time.
+	span := int64(w) * f
+	if firstCol+span > int64(py.width) {
+		span = int64(py.width) - firstCol
+	}
+	buf := make([]byte, span)
+	for ty := 0; ty < h; ty++ {
+		for i := range summaries {
+			summaries[i].Reset()
+		}
+		for dy := int64(0); dy < f; dy++ {
+			row := (int64(y*py.tileSize+ty))*f + dy
+			offset := row*int64(py.width) + firstCol
+			if offset >= py.size {
+				break
+			}
+			n := span
+			if offset+n > py.size {
+				n = py.size - offset
+			}
+			if _, err := r.ReadAt(buf[:n], offset); err != nil && err != io.EOF {
+				return nil, err
+			}
+			for tx := 0; int64(tx)*f < n && tx < w; tx++ {
+				end := int64(tx+1) * f
+				if end > n {
+					end = n
+				}
+				summaries[tx].Add(buf[int64(tx)*f : end])
+			}
+		}
+		for tx := range summaries {
+			if summaries[tx].Len() == 0 {
+				continue
+			}
+			col := c.Color(&summaries[tx])
+			m.SetNRGBA(tx, ty, color.NRGBA{col[0], col[1], col[2], 255})
+		}
+	}
+	return m, nil
+}
+
+// Generate writes every tile of the pyramid for the file r of the given size
+// to dir, in parallel. Tiles that already exist are skipped, and every tile
+// is written to a temporary file that is renamed into place when complete,
+// so an interrupted run can be resumed by running it again. A manifest next
+// to the tiles records the file's size and a hash of its ends, the palette
+// and the options, and a run with different ones is refused rather than
+// mixed in with the old tiles.
+func Generate(ctx context.Context, r io.ReaderAt, size int64, p Palette, dir string, opts *Options) error {
+	py, err := New(size, opts)
+	if err != nil {
+		return err
+	}
+	name := opts.Name
+	if name == "" {
+		name = "tiles"
+	}
+	root := dir
+	if opts.Format == DeepZoom {
+		root = filepath.Join(dir, name+"_files")
+	}
+	m, err := newManifest(r, py, p)
+	if err != nil {
+		return err
+	}
+	if err := checkManifest(root, m); err != nil {
+		return err
+	}
+	if opts.Format == DeepZoom {
+		if err := writeDZI(filepath.Join(dir, name+".dzi"), py); err != nil {
+			return err
+		}
+	}
+
+	type job struct {
+		level, x, y int
+		path        string
+	}
+	total := 0
+	for level := 0; level <= py.maxLevel; level++ {
+		across, down := py.Tiles(level)
+		total += across * down
+	}
+	jobs := make(chan job)
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+		done     int
+	)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				err := py.writeTile(r, p, j.level, j.x, j.y, j.path, opts.Format == DeepZoom)
+				mu.Lock()
+				if err != nil && firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				done++
+				n := done
+				mu.Unlock()
+				if opts.Progress != nil {
+					opts.Progress(n, total)
+				}
+			}
+		}()
+	}
+
+feed:
+	for level := 0; level <= py.maxLevel; level++ {
+		across, down := py.Tiles(level)
+		for x := 0; x < across; x++ {
+			for y := 0; y < down; y++ {
+				var path string
+				switch opts.Format {
+				case DeepZoom:
+					path = filepath.Join(root, fmt.Sprint(level), fmt.Sprintf("%d_%d.png", x, y))
+				default:
+					path = filepath.Join(root, fmt.Sprint(level), fmt.Sprint(x), fmt.Sprintf("%d.png", y))
+				}
+				select {
+				case jobs <- job{level, x, y, path}:
+				case <-ctx.Done():
+					break feed
+				}
+			}
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+// writeTile renders one tile to path, unless it already exists.
+func (py *Pyramid) writeTile(r io.ReaderAt, p Palette, level, x, y int, path string, crop bool) error {
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	m, err := py.Tile(r, p, level, x, y, crop)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(path), ".tile-*.png")
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(tmp, m); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func writeDZI(path string, py *Pyramid) error {
+	w, h := py.LevelSize(py.maxLevel)
+	dzi := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<Image xmlns="http://schemas.microsoft.com/deepzoom/2008" Format="png" Overlap="0" TileSize="%d">
+  <Size Width="%d" Height="%d"/>
+</Image>
+`, py.tileSize, w, h)
+	return ioutil.WriteFile(path, []byte(dzi), 0644)
+}
@@ -0,0 +1,143 @@
+// Package trigram turns binary data into a 3D point cloud, with one point per
+// triple of consecutive bytes, for inspection in standard 3D viewers.
+package trigram
+
+import (
+	"bufio"
+	"fmt"
+	"image/color"
+	"io"
+)
+
+type rgb = [3]byte
+
+type Palette interface {
+	// Select returns 8bpc R,G,B values for a given byte value
+	Select(b byte) rgb
+	// Nearest returns the byte value corresponding to the approximate color
+	Nearest(c color.Color) byte
+}
+
+// Point is a point of the cloud.
+type Point struct {
+	// X, Y and Z are the first, middle and last byte of the triple, or the
+	// center of the voxel when aggregated.
+	X, Y, Z float64
+	// Middle is the byte whose palette color is used for the point.
+	Middle byte
+	// Count is the number of triples represented by the point.
+	Count uint64
+}
+
+// Points returns the point cloud of data. If voxel is 0, every triple is a
+// point of its own. Otherwise the byte cube is divided into voxels that are
+// voxel bytes wide, and every voxel holding at least one triple is a point
+// at the center of the voxel. Voxels are colored by the most common middle
+// byte among the triples that fall in them.
+func Points(data []byte, voxel int) ([]Point, error) {
+	if voxel < 0 || voxel > 256 {
+		return nil, fmt.Errorf("voxel size must be between 0 and 256")
+	}
+	if len(data) < 3 {
+		return nil, nil
+	}
+	if voxel == 0 {
+		result := make([]Point, len(data)-2)
+		for i := range result {
+			result[i] = Point{
+				X:      float64(data[i]),
+				Y:      float64(data[i+1]),
+				Z:      float64(data[i+2]),
+				Middle: data[i+1],
+				Count:  1,
+			}
+		}
+		return result, nil
+	}
+
+	// Voxels are counted in dense arrays indexed by (x*n+y)*n+z, which take
+	// the same memory however many of them are occupied, and list them in
+	// order.
+	n := (256 + voxel - 1) / voxel
+	counts := make([]uint64, n*n*n)
+	// Every middle byte in a voxel is within voxel of the start of the
+	// voxel, so a voxel needs only that many counters to pick its color,
+	// and none when voxels are one byte wide.
+	var middles []uint64
+	if voxel > 1 {
+		middles = make([]uint64, n*n*n*voxel)
+	}
+	for i := 0; i+2 < len(data); i++ {
+		v := (int(data[i])/voxel*n+int(data[i+1])/voxel)*n + int(data[i+2])/voxel
+		counts[v]++
+		if middles != nil {
+			middles[v*voxel+int(data[i+1])%voxel]++
+		}
+	}
+	var result []Point
+	half := float64(voxel-1) / 2
+	for v, count := range counts {
+		if count == 0 {
+			continue
+		}
+		x, y, z := v/(n*n), v/n%n, v%n
+		middle := y * voxel
+		if middles != nil {
+			middle += mostCommon(middles[v*voxel : (v+1)*voxel])
+		}
+		result = append(result, Point{
+			X:      float64(x*voxel) + half,
+			Y:      float64(y*voxel) + half,
+			Z:      float64(z*voxel) + half,
+			Middle: byte(middle),
+			Count:  count,
+		})
+	}
+	return result, nil
+}
+
+// mostCommon returns the index of the largest count, the first if tied.
+func mostCommon(counts []uint64) int {
+	best := 0
+	for i := range counts {
+		if counts[i] > counts[best] {
+			best = i
+		}
+	}
+	return best
+}
+
+// WritePLY writes the points as an ASCII PLY file with per-vertex colors
+// and counts.
+func WritePLY(w io.Writer, points []Point, p Palette) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "ply\n")
+	fmt.Fprintf(bw, "format ascii 1.0\n")
+	fmt.Fprintf(bw, "comment bytecolor trigram point cloud\n")
+	fmt.Fprintf(bw, "element vertex %d\n", len(points))
+	fmt.Fprintf(bw, "property float x\n")
+	fmt.Fprintf(bw, "property float y\n")
+	fmt.Fprintf(bw, "property float z\n")
+	fmt.Fprintf(bw, "property uchar red\n")
+	fmt.Fprintf(bw, "property uchar green\n")
+	fmt.Fprintf(bw, "property uchar blue\n")
+	fmt.Fprintf(bw, "property uint count\n")
+	fmt.Fprintf(bw, "end_header\n")
+	for _, pt := range points {
+		c := p.Select(pt.Middle)
+		fmt.Fprintf(bw, "%g %g %g %d %d %d %d\n", pt.X, pt.Y, pt.Z, c[0], c[1], c[2], pt.Count)
+	}
+	return bw.Flush()
+}
+
+// WriteOBJ writes the points as OBJ vertices, using the widely supported
+// "v x y z r g b" extension for vertex colors. OBJ has no room for counts.
+func WriteOBJ(w io.Writer, points []Point, p Palette) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# bytecolor trigram point cloud, %d points\n", len(points))
+	for _, pt := range points {
+		c := p.Select(pt.Middle)
+		fmt.Fprintf(bw, "v %g %g %g %.4f %.4f %.4f\n", pt.X, pt.Y, pt.Z, float64(c[0])/255.0, float64(c[1])/255.0, float64(c[2])/255.0)
+	}
+	return bw.Flush()
+}
@@ -15,13 +15,18 @@ func (p palette) Select(b byte) [3]byte {
 	return colors[b]
 }
 
+// Distance returns the distance between two colors, as used by Nearest.
+func (p palette) Distance(c1, c2 colorful.Color) float64 {
+	return c1.DistanceRgb(c2)
+}
+
 func (p palette) Nearest(c color.Color) byte {
 	best := byte(0)
 	bestDist := math.MaxFloat64
 	for i := 0; i < 256; i++ {
 		rgb := p.Select(byte(i))
 		col, _ := colorful.MakeColor(c)
-		dist := col.DistanceRgb(colorful.Color{float64(rgb[0]) / 255.0, float64(rgb[1]) / 255.0, float64(rgb[2]) / 255.0})
+		dist := p.Distance(col, colorful.Color{R: float64(rgb[0]) / 255.0, G: float64(rgb[1]) / 255.0, B: float64(rgb[2]) / 255.0})
 		if dist < bestDist {
 			bestDist = dist
 			best = byte(i)